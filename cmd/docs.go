@@ -0,0 +1,128 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tnaucoin/stringer/internal/docs"
+	"github.com/tnaucoin/stringer/types"
+)
+
+var (
+	readmePath  string
+	docsInPlace bool
+	docsCheck   bool
+	usesRepo    string
+)
+
+// docsCmd represents the docs command
+var docsCmd = &cobra.Command{
+	Use:   "docs [path]",
+	Short: "generate Markdown documentation for actions",
+	Long: `Render the inputs, outputs and a usage snippet of every action as Markdown.
+
+The docs are printed to stdout unless --readme or --in-place is given, both
+replace the region between ` + docs.StartMarker + ` and ` + docs.EndMarker + `
+and leave the rest of the README untouched.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
+		}
+		if usesRepo != "" && !strings.Contains(usesRepo, "@") {
+			fmt.Println("--uses-repo must look like my-org/my-repo@v1")
+			os.Exit(1)
+		}
+
+		actions, _ := collectActions(cmd, root, nil)
+		if len(actions) == 0 {
+			fmt.Println("No actions found")
+			return
+		}
+
+		if readmePath == "" && !docsInPlace {
+			fmt.Print(docs.MarkdownAll(actions, usesFor))
+			return
+		}
+
+		stale := false
+		if readmePath != "" {
+			stale = updateReadme(readmePath, docs.MarkdownAll(actions, usesFor), false) || stale
+		}
+		if docsInPlace {
+			for _, a := range actions {
+				if a.Provenance.Kind != types.SourceLocal {
+					continue
+				}
+				path := filepath.Join(filepath.Dir(a.Path), "README.md")
+				stale = updateReadme(path, docs.Markdown(a, usesFor(a)), true) || stale
+			}
+		}
+		if docsCheck && stale {
+			os.Exit(1)
+		}
+	},
+}
+
+// updateReadme writes generated into the marked region of the README at
+// path and reports whether it was out of date. A missing README is only
+// created when create is set.
+func updateReadme(path, generated string, create bool) bool {
+	current, err := os.ReadFile(path)
+	var updated string
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && create:
+		updated = docs.Region(generated)
+	case err != nil:
+		fmt.Println("Failed to read README:", err)
+		os.Exit(1)
+	default:
+		updated, err = docs.UpdateRegion(string(current), generated)
+		if err != nil {
+			fmt.Printf("Skipping %s: %v\n", path, err)
+			return false
+		}
+	}
+
+	if updated == string(current) {
+		return false
+	}
+	if docsCheck {
+		fmt.Printf("%s is out of date\n", path)
+		return true
+	}
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		fmt.Println("Failed to write README:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Updated %s\n", path)
+	return true
+}
+
+// usesFor is the uses: reference shown for an action, local actions are
+// shown as published in --uses-repo when it is set
+func usesFor(a types.CompositeAction) string {
+	if usesRepo == "" || a.Provenance.Kind != types.SourceLocal {
+		return ""
+	}
+	repo, version, _ := strings.Cut(usesRepo, "@")
+	return types.UsesReference(repo, version, a.Provenance.Path)
+}
+
+func init() {
+	docsCmd.Flags().StringVar(&readmePath, "readme", "", "README to update with the docs of every action")
+	docsCmd.Flags().BoolVar(&docsInPlace, "in-place", false, "Update or create the README.md next to each local action")
+	docsCmd.Flags().BoolVar(&docsCheck, "check", false, "Don't write anything, exit non-zero if a README is out of date")
+	docsCmd.Flags().StringVar(&usesRepo, "uses-repo", "", "Show local actions as published in this repo (my-org/my-repo@v1)")
+	addGithubFlags(docsCmd)
+	rootCmd.AddCommand(docsCmd)
+}
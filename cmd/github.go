@@ -0,0 +1,81 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnaucoin/stringer/internal/auth"
+	"github.com/tnaucoin/stringer/internal/remote"
+)
+
+// flags shared by every command that can read from GitHub
+var (
+	repo       string
+	ref        string
+	token      string
+	apiURL     string
+	org        string
+	topic      string
+	visibility string
+	nameGlob   string
+	workers    int
+)
+
+func addGithubFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&repo, "repo", "", "Github repo to scan (my-org/my-repo)")
+	cmd.Flags().StringVar(&ref, "ref", "main", "Git ref to use when scanning a Github repo (e.g. branch, tag")
+	cmd.Flags().StringVar(&token, "token", "", "Github token to use when scanning a Github repo")
+	cmd.Flags().StringVar(&apiURL, "api-url", remote.DefaultBaseURL, "Github API base URL (e.g. https://github.example.com/api/v3 for GHES)")
+	cmd.Flags().StringVar(&org, "org", "", "Github organization to scan every repository of")
+	cmd.Flags().StringVar(&topic, "topic", "", "Only scan org repositories with this topic")
+	cmd.Flags().StringVar(&visibility, "visibility", "", "Only scan org repositories with this visibility (public, private, internal)")
+	cmd.Flags().StringVar(&nameGlob, "match", "", "Only scan org repositories whose name matches this glob (e.g. 'actions-*')")
+	cmd.Flags().IntVar(&workers, "concurrency", remote.DefaultConcurrency, "Number of org repositories to scan concurrently")
+	cmd.MarkFlagsMutuallyExclusive("org", "repo")
+}
+
+func newGithubFetcher() *remote.Fetcher {
+	userToken, err := auth.ResolveGithubToken(token)
+	if err != nil {
+		fmt.Printf("failed to resolve github token: %v\n", err)
+		os.Exit(1)
+	}
+	gitFetch := remote.NewGithubFetcher(userToken)
+	gitFetch.BaseURL = apiURL
+	return gitFetch
+}
+
+func orgOptions(cmd *cobra.Command) remote.OrgOptions {
+	opts := remote.OrgOptions{
+		Org:         org,
+		Topic:       topic,
+		Visibility:  visibility,
+		NameGlob:    nameGlob,
+		Concurrency: workers,
+	}
+	// an explicit --ref applies to every repo, otherwise each
+	// repo is scanned at its default branch
+	if cmd.Flags().Changed("ref") {
+		opts.Ref = ref
+	}
+	return opts
+}
+
+// exitOnGithubError reports a failed fetch of target and exits
+func exitOnGithubError(target string, err error) {
+	if err == nil {
+		return
+	}
+	var rlErr *remote.RateLimitError
+	if errors.As(err, &rlErr) {
+		fmt.Printf("stopped scanning %s: %v\n", target, rlErr)
+		os.Exit(1)
+	}
+	fmt.Printf("failed to scan github %s: %v\n", target, err)
+	os.Exit(1)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnaucoin/stringer/internal/lint"
+	"github.com/tnaucoin/stringer/parser"
+)
+
+var lintFormat string
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint [path]",
+	Short: "check caller inputs and expression references of actions and workflows",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
+		}
+
+		catalog, workflows := loadCatalogAndWorkflows(cmd, root)
+		// only actions of a local tree are linted, remote ones resolve calls
+		lintRoot := ""
+		if org == "" && repo == "" {
+			lintRoot = parser.FindRepoRoot(root)
+		}
+		diags := lint.CheckCallers(catalog, workflows)
+		diags = append(diags, lint.CheckExpressions(catalog, workflows, lintRoot)...)
+		lint.Sort(diags)
+
+		if err := lint.Write(os.Stdout, diags, lintFormat); err != nil {
+			fmt.Println("Error: ", err)
+			os.Exit(1)
+		}
+		if lint.HasErrors(diags) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	lintCmd.Flags().StringVar(&catalogPath, "catalog", ".stringercache.json", "Action catalog to resolve against (scan cache or --output file)")
+	lintCmd.Flags().StringVarP(&lintFormat, "format", "f", lint.FormatText, "Output format (text, json, github)")
+	addGithubFlags(lintCmd)
+	rootCmd.AddCommand(lintCmd)
+}
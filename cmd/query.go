@@ -0,0 +1,106 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tnaucoin/stringer/internal/format"
+	"github.com/tnaucoin/stringer/internal/store"
+)
+
+var (
+	queryStore   string
+	queryName    string
+	queryInput   string
+	queryRepo    string
+	queryHistory string
+	queryFormat  string
+)
+
+// queryCmd represents the query command
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "query a catalog store by action name, input or repo",
+	Long: `Query the latest revision of every source recorded in a catalog store,
+or list the revisions of one source with --history.
+
+Stores are written by ` + "`stringer scan --store`" + `, .db files are an embedded
+database keeping every revision, anything else a JSON cache keeping the
+latest one.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(queryStore); err != nil {
+			fmt.Printf("No store at %s, run `stringer scan --store %s` first\n", queryStore, queryStore)
+			os.Exit(1)
+		}
+		s, err := store.Open(queryStore)
+		if err != nil {
+			fmt.Println("Failed to open store:", err)
+			os.Exit(1)
+		}
+		defer s.Close()
+
+		if queryHistory != "" {
+			printHistory(s, historySource(queryHistory))
+			return
+		}
+
+		formatter, err := format.New(queryFormat, format.Options{})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		actions, err := s.Query(store.Query{Name: queryName, Input: queryInput, Repo: queryRepo})
+		if err != nil {
+			fmt.Println("Failed to query store:", err)
+			os.Exit(1)
+		}
+		if err := formatter.Format(os.Stdout, actions); err != nil {
+			fmt.Println("Failed to format actions:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// historySource names the source --history refers to, a local directory
+// or otherwise a repository
+func historySource(name string) string {
+	if info, err := os.Stat(name); err == nil && info.IsDir() {
+		if abs, err := filepath.Abs(name); err == nil {
+			return store.LocalTarget(abs)
+		}
+	}
+	return store.RepoSource(name)
+}
+
+func printHistory(s store.Store, source string) {
+	revs, err := s.History(source)
+	if err != nil {
+		fmt.Println("Failed to read history:", err)
+		os.Exit(1)
+	}
+	if len(revs) == 0 {
+		fmt.Printf("No revisions of %s\n", source)
+		return
+	}
+	for _, rev := range revs {
+		fmt.Printf("%s  %s  %d actions\n", rev.SavedAt.Local().Format(time.DateTime), rev.Rev, rev.Count)
+	}
+}
+
+func init() {
+	queryCmd.Flags().StringVar(&queryStore, "store", ".stringercache.json", "Catalog store to query (.db for the embedded database, JSON otherwise)")
+	queryCmd.Flags().StringVar(&queryName, "name", "", "Only actions whose name contains this")
+	queryCmd.Flags().StringVar(&queryInput, "input", "", "Only actions declaring this input")
+	queryCmd.Flags().StringVar(&queryRepo, "repo", "", "Only actions of this repository (owner/name)")
+	queryCmd.Flags().StringVar(&queryHistory, "history", "", "List the revisions of a repository or local directory instead")
+	queryCmd.Flags().StringVarP(&queryFormat, "format", "f", format.Text, "Output format ("+strings.Join(format.Names(), ", ")+")")
+	rootCmd.AddCommand(queryCmd)
+}
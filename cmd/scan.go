@@ -6,9 +6,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/tnaucoin/stringer/internal/auth"
+	"github.com/tnaucoin/stringer/internal/format"
+	"github.com/tnaucoin/stringer/internal/lint"
 	"github.com/tnaucoin/stringer/internal/remote"
 	"github.com/tnaucoin/stringer/internal/store"
 	"github.com/tnaucoin/stringer/parser"
@@ -19,81 +22,227 @@ var (
 	outputPath string
 	cachePath  string
 	forceScan  bool
-	repo       string
-	ref        string
-	token      string
+	kind       string
+	strict     bool
+
+	scanFormat   string
+	templatePath string
+	storePath    string
 )
 
 // scanCmd represents the scan command
 var scanCmd = &cobra.Command{
 	Use:   "scan [path]",
-	Short: "scan a directory for Github CompositeActions",
-	Args:  cobra.ExactArgs(1),
+	Short: "scan a directory, repo or organization for Github Actions",
+	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		var actions []types.CompositeAction
-		root := args[0]
-		if repo != "" {
-			opts := remote.Options{
-				Repo: repo,
-				Ref:  ref,
-			}
-			userToken, err := auth.ResolveGithubToken(token)
-			if err != nil {
-				fmt.Printf("failed to resolve github token: %v\n", err)
-				os.Exit(1)
-			}
-			gitFetch := remote.NewGithubFetcher(userToken)
-			repoActions, err := gitFetch.FetchCompositeActionsFromRepo(opts)
-			if err != nil {
-				fmt.Printf("failed to fetch github repo %s with ref: %s: %v\n", opts.Repo, opts.Ref, err)
-				os.Exit(1)
-			}
-			actions = append(actions, repoActions...)
-		} else {
-			localFileActions, err := parser.ParseCompositeActions(root)
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
+		}
+		if kind != "" && !validKind(types.ActionKind(kind)) {
+			fmt.Printf("unknown action kind %q, expected composite, javascript or docker\n", kind)
+			os.Exit(1)
+		}
+		formatter, err := newFormatter()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 
-			if err != nil {
-				fmt.Println("Error: ", err)
+		cache, err := store.OpenCache(cachePath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error: ", err)
+			os.Exit(1)
+		}
+		cache.Refresh = forceScan
+		actions, diags := collectActions(cmd, root, cache)
+		if err := cache.Save(); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to write interal cache:", err)
+			os.Exit(1)
+		}
+		if storePath != "" {
+			if err := saveRevisions(storePath, scanRevisions(root, actions, cache)); err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to save to store:", err)
 				os.Exit(1)
 			}
-			actions = append(actions, localFileActions...)
 		}
 
-		if len(actions) == 0 {
-			fmt.Println("No composite actions found")
-			return
+		printDiagnostics(diags)
+		if strict && failsStrict(diags) {
+			os.Exit(1)
+		}
+
+		if kind != "" {
+			actions = parser.FilterKind(actions, types.ActionKind(kind))
 		}
 
-		for _, a := range actions {
-			fmt.Printf("🔹 %s — %s\n", a.Name, a.Description)
-			fmt.Printf("   Inputs: %v\n", a.Inputs)
-			fmt.Printf("   Outputs: %v\n\n", a.Outputs)
+		if err := formatter.Format(os.Stdout, actions); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to format actions:", err)
+			os.Exit(1)
+		}
+		if len(actions) == 0 {
+			return
 		}
 
 		if outputPath != "" {
 			if err := store.SaveActions(actions, outputPath); err != nil {
-				fmt.Println("Failed to write output JSON:", err)
+				fmt.Fprintln(os.Stderr, "Failed to write output JSON:", err)
 				os.Exit(1)
 			}
-		} else {
-			valid, _ := store.IsCacheValid(root, cachePath)
-			if !valid || forceScan {
-				if err := store.SaveActionsWithHash(actions, root, cachePath); err != nil {
-					fmt.Println("failed to write interal cache:", err)
-					os.Exit(1)
-				}
-				fmt.Println("Updating internal actions cache")
-			}
 		}
 	},
 }
 
+// newFormatter builds the --format formatter, --template implies the
+// template format
+func newFormatter() (format.Formatter, error) {
+	opts := format.Options{}
+	if templatePath != "" {
+		data, err := os.ReadFile(templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		opts.Template = string(data)
+		if scanFormat == format.Text {
+			scanFormat = format.Template
+		}
+	}
+	return format.New(scanFormat, opts)
+}
+
+// collectActions parses the actions of the org, repo or local directory
+// the command was pointed at, diagnostics are only collected locally. A
+// nil cache parses everything.
+func collectActions(cmd *cobra.Command, root string, cache *store.Cache) ([]types.CompositeAction, []types.Diagnostic) {
+	switch {
+	case org != "":
+		actions, err := cachedFetcher(cache).FetchActionsFromOrg(orgOptions(cmd))
+		exitOnGithubError(org, err)
+		return actions, nil
+	case repo != "":
+		opts := remote.Options{
+			Repo: repo,
+			Ref:  ref,
+		}
+		actions, err := cachedFetcher(cache).FetchActionsFromRepo(opts)
+		exitOnGithubError(opts.Repo+"@"+opts.Ref, err)
+		return actions, nil
+	case cache != nil:
+		// local scans only parse the files that changed since the cached
+		// scan of root
+		entry, stats, err := cache.ScanDirectory(root)
+		if err != nil {
+			fmt.Println("Error: ", err)
+			os.Exit(1)
+		}
+		if stats.Changed {
+			fmt.Fprintf(os.Stderr, "Updated internal actions cache (%d parsed, %d cached, %d removed)\n",
+				stats.Parsed, stats.Reused, stats.Removed)
+		}
+		return entry.Actions, entry.Diagnostics()
+	}
+	actions, diags, err := parser.ParseActions(root)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		os.Exit(1)
+	}
+	return actions, diags
+}
+
+// cachedFetcher is a GitHub fetcher that reuses the repos in cache
+func cachedFetcher(cache *store.Cache) *remote.Fetcher {
+	fetcher := newGithubFetcher()
+	if cache != nil {
+		fetcher.Cache = cache
+	}
+	return fetcher
+}
+
+// scanRevisions groups scanned actions into the revisions of their
+// sources: remote actions by repo and commit, a local scan by its root and
+// content hash
+func scanRevisions(root string, actions []types.CompositeAction, cache *store.Cache) []store.Revision {
+	if org == "" && repo == "" {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return nil
+		}
+		source := store.LocalTarget(absRoot)
+		entry, ok := cache.Entry(source)
+		if !ok {
+			return nil
+		}
+		return []store.Revision{{Source: source, Rev: entry.Hash, Actions: actions}}
+	}
+
+	var revs []store.Revision
+	index := map[string]int{}
+	for _, a := range actions {
+		source := store.RepoSource(a.Provenance.Repo)
+		i, ok := index[source]
+		if !ok {
+			i = len(revs)
+			index[source] = i
+			revs = append(revs, store.Revision{Source: source, Rev: a.Provenance.SHA})
+		}
+		revs[i].Actions = append(revs[i].Actions, a)
+	}
+	return revs
+}
+
+// saveRevisions records revs in the store at path
+func saveRevisions(path string, revs []store.Revision) error {
+	s, err := store.Open(path)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	for _, rev := range revs {
+		if err := s.Save(rev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printDiagnostics lists the errors and warnings found while parsing and
+// a summary line, notices are only counted
+func printDiagnostics(diags []types.Diagnostic) {
+	if len(diags) == 0 {
+		return
+	}
+	lint.Sort(diags)
+	for _, d := range diags {
+		if d.Severity != types.SeverityNotice {
+			fmt.Fprintln(os.Stderr, d.String())
+		}
+	}
+	counts := parser.Summary(diags)
+	fmt.Fprintf(os.Stderr, "⚠️  %d errors, %d warnings, %d notices while parsing\n\n",
+		counts[types.SeverityError], counts[types.SeverityWarning], counts[types.SeverityNotice])
+}
+
+// failsStrict reports whether diags should fail a --strict scan. Warnings
+// flag actions that are tolerated, e.g. legacy ones missing a name, so
+// only errors fail it.
+func failsStrict(diags []types.Diagnostic) bool {
+	return parser.Summary(diags)[types.SeverityError] > 0
+}
+
+func validKind(k types.ActionKind) bool {
+	return k == types.KindComposite || k == types.KindJavaScript || k == types.KindDocker
+}
+
 func init() {
 	scanCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Path to write parsed actions to JSON")
 	scanCmd.Flags().StringVar(&cachePath, "cache", ".stringercache.json", "Path to store internal action cache")
 	scanCmd.Flags().BoolVar(&forceScan, "force", false, "Force cache refresh")
-	scanCmd.Flags().StringVar(&repo, "repo", "", "Github repo to scan composite actions from (my-org/my-repo)")
-	scanCmd.Flags().StringVar(&ref, "ref", "main", "Git ref to use when scanning a Github repo (e.g. branch, tag")
-	scanCmd.Flags().StringVar(&token, "token", "", "Github token to use when scanning a Github repo")
+	scanCmd.Flags().StringVar(&kind, "kind", "", "Only keep actions of this kind (composite, javascript, docker)")
+	scanCmd.Flags().BoolVar(&strict, "strict", false, "Exit non-zero when any file fails to parse")
+	scanCmd.Flags().StringVarP(&scanFormat, "format", "f", format.Text, "Output format ("+strings.Join(format.Names(), ", ")+")")
+	scanCmd.Flags().StringVar(&templatePath, "template", "", "Go text/template file to render the actions with")
+	scanCmd.Flags().StringVar(&storePath, "store", "", "Also record the scan in this catalog store (.db for the embedded database, JSON otherwise)")
+	addGithubFlags(scanCmd)
 	rootCmd.AddCommand(scanCmd)
 }
@@ -0,0 +1,102 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnaucoin/stringer/internal/remote"
+	"github.com/tnaucoin/stringer/internal/server"
+	"github.com/tnaucoin/stringer/internal/store"
+	"github.com/tnaucoin/stringer/types"
+)
+
+var serveAddr string
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve [path]",
+	Short: "serve the action catalog over an HTTP API",
+	Long: `Serve the cached action catalog as JSON:
+
+  GET  /api/actions?q=&kind=    list and search actions
+  GET  /api/actions/{id}        a single action
+  GET  /api/actions/{id}/usage  a usage snippet for the action
+  POST /api/rescan              scan again and replace the catalog
+
+Responses carry the cache hash as ETag and honour If-None-Match.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
+		}
+
+		var catalog *store.CacheFile
+		var rescan server.RescanFunc
+		cache, err := store.OpenCache(catalogPath)
+		if err == nil {
+			// the token is resolved before serving, a rescan can't exit
+			var fetcher *remote.Fetcher
+			if org != "" || repo != "" {
+				fetcher = cachedFetcher(cache)
+			}
+			rescan = rescanCatalog(cmd, root, cache, fetcher)
+			catalog = cache.Catalog()
+			if len(cache.Targets()) == 0 {
+				fmt.Printf("No catalog at %s, scanning\n", catalogPath)
+				catalog, err = rescan()
+			}
+		} else {
+			// an --output file is served as is, rescans would overwrite it
+			var actions []types.CompositeAction
+			actions, err = store.LoadCatalog(catalogPath)
+			catalog = &store.CacheFile{Actions: actions}
+		}
+		if err != nil {
+			fmt.Println("Failed to load catalog:", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Serving %d actions on http://%s\n", len(catalog.Actions), serveAddr)
+		if err := http.ListenAndServe(serveAddr, server.New(catalog, rescan).Handler()); err != nil {
+			fmt.Println("Error: ", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// rescanCatalog scans the same source as `stringer scan` would into
+// cache and saves it, remote sources through fetcher. The merged catalog
+// of the cache is served, its hash becomes the new ETag.
+func rescanCatalog(cmd *cobra.Command, root string, cache *store.Cache, fetcher *remote.Fetcher) server.RescanFunc {
+	return func() (*store.CacheFile, error) {
+		var err error
+		switch {
+		case org != "":
+			_, err = fetcher.FetchActionsFromOrg(orgOptions(cmd))
+		case repo != "":
+			_, err = fetcher.FetchActionsFromRepo(remote.Options{Repo: repo, Ref: ref})
+		default:
+			_, _, err = cache.ScanDirectory(root)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := cache.Save(); err != nil {
+			return nil, err
+		}
+		return cache.Catalog(), nil
+	}
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&catalogPath, "catalog", ".stringercache.json", "Action catalog to serve, local rescans rewrite it")
+	addGithubFlags(serveCmd)
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnaucoin/stringer/internal/site"
+	"github.com/tnaucoin/stringer/internal/store"
+)
+
+var (
+	siteOut       string
+	siteTitle     string
+	siteGithubURL string
+)
+
+// siteCmd represents the site command
+var siteCmd = &cobra.Command{
+	Use:   "site",
+	Short: "build a static, searchable HTML catalog of scanned actions",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions, err := store.LoadCatalog(catalogPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Printf("No catalog at %s, run `stringer scan` first or pass --catalog\n", catalogPath)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Println("Failed to load catalog:", err)
+			os.Exit(1)
+		}
+
+		opts := site.Options{Title: siteTitle, GithubURL: siteGithubURL}
+		if err := site.Build(siteOut, actions, opts); err != nil {
+			fmt.Println("Failed to build site:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %d action pages to %s\n", len(actions), siteOut)
+	},
+}
+
+func init() {
+	siteCmd.Flags().StringVar(&siteOut, "out", "site", "Directory to write the site to")
+	siteCmd.Flags().StringVar(&catalogPath, "catalog", ".stringercache.json", "Action catalog to build the site from (scan cache or --output file)")
+	siteCmd.Flags().StringVar(&siteTitle, "title", site.DefaultTitle, "Title shown on every page")
+	siteCmd.Flags().StringVar(&siteGithubURL, "github-url", site.DefaultGithubURL, "GitHub web URL source links point to")
+	rootCmd.AddCommand(siteCmd)
+}
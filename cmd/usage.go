@@ -0,0 +1,182 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tnaucoin/stringer/internal/remote"
+	"github.com/tnaucoin/stringer/internal/store"
+	"github.com/tnaucoin/stringer/internal/usage"
+	"github.com/tnaucoin/stringer/parser"
+	"github.com/tnaucoin/stringer/types"
+)
+
+var (
+	catalogPath    string
+	usageJSON      bool
+	usageAction    string
+	showUnresolved bool
+)
+
+// usageCmd represents the usage command
+var usageCmd = &cobra.Command{
+	Use:   "usage [path]",
+	Short: "report which workflows call each cataloged action",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
+		}
+
+		catalog, workflows := loadCatalogAndWorkflows(cmd, root)
+		report := usage.BuildReport(catalog, workflows)
+		if usageAction != "" {
+			report.Actions = filterUsage(report.Actions, usageAction)
+		}
+
+		if usageJSON {
+			data, err := json.MarshalIndent(report, "", "	")
+			if err != nil {
+				fmt.Println("Failed to marshal usage report:", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		if len(report.Actions) == 0 {
+			fmt.Println("No cataloged actions found, run `stringer scan` first or pass --catalog")
+			return
+		}
+
+		for _, u := range report.Actions {
+			fmt.Printf("🔹 %s (%s)\n", u.Action.Name, actionLabel(u.Action))
+			if len(u.Consumers) == 0 {
+				fmt.Printf("   no consumers\n\n")
+				continue
+			}
+			fmt.Printf("   %d consumers\n", len(u.Consumers))
+			for _, c := range u.Consumers {
+				printConsumer(c)
+			}
+			fmt.Println()
+		}
+
+		if showUnresolved && len(report.Unresolved) > 0 {
+			fmt.Println("Not in catalog:")
+			for _, c := range report.Unresolved {
+				printConsumer(c)
+			}
+		}
+	},
+}
+
+// loadCatalogAndWorkflows reads the catalog file and collects workflows
+// from GitHub or the local tree. Local actions are always parsed from the
+// tree so ./ references resolve without a prior scan.
+func loadCatalogAndWorkflows(cmd *cobra.Command, root string) ([]types.CompositeAction, []types.Workflow) {
+	catalog, err := store.LoadCatalog(catalogPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Println("Failed to load catalog:", err)
+		os.Exit(1)
+	}
+
+	var workflows []types.Workflow
+	switch {
+	case org != "":
+		workflows, err = newGithubFetcher().FetchWorkflowsFromOrg(orgOptions(cmd))
+		exitOnGithubError(org, err)
+	case repo != "":
+		opts := remote.Options{Repo: repo, Ref: ref}
+		workflows, err = newGithubFetcher().FetchWorkflowsFromRepo(opts)
+		exitOnGithubError(opts.Repo+"@"+opts.Ref, err)
+	default:
+		workflows, err = parser.ParseWorkflows(root)
+		if err != nil {
+			fmt.Println("Error: ", err)
+			os.Exit(1)
+		}
+		local, _, err := parser.ParseActions(root)
+		if err != nil {
+			fmt.Println("Error: ", err)
+			os.Exit(1)
+		}
+		catalog = mergeCatalog(catalog, local)
+	}
+	return catalog, workflows
+}
+
+// mergeCatalog replaces the local actions of catalog with actions freshly
+// parsed from the tree, cached copies may be stale and have lost the line
+// numbers diagnostics point at
+func mergeCatalog(catalog, actions []types.CompositeAction) []types.CompositeAction {
+	var merged []types.CompositeAction
+	for _, a := range catalog {
+		if a.Provenance.Kind != types.SourceLocal {
+			merged = append(merged, a)
+		}
+	}
+	return append(merged, actions...)
+}
+
+// filterUsage keeps the actions whose name or uses reference contains q
+func filterUsage(actions []usage.ActionUsage, q string) []usage.ActionUsage {
+	q = strings.ToLower(q)
+	var filtered []usage.ActionUsage
+	for _, u := range actions {
+		if strings.Contains(strings.ToLower(u.Action.Name), q) || strings.Contains(strings.ToLower(u.Action.Provenance.Uses), q) {
+			filtered = append(filtered, u)
+		}
+	}
+	return filtered
+}
+
+func actionLabel(a types.CompositeAction) string {
+	if a.Provenance.Uses != "" {
+		return a.Provenance.Uses
+	}
+	return a.Provenance.Path
+}
+
+func printConsumer(c usage.Consumer) {
+	location := c.Source
+	if c.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, c.Line)
+	}
+	caller := "step " + c.Step
+	if c.Job != "" {
+		caller = "job " + c.Job + ", " + caller
+	}
+	fmt.Printf("   - %s (%s) uses %s\n", location, caller, c.Uses)
+	if len(c.With) > 0 {
+		keys := make([]string, 0, len(c.With))
+		for k := range c.With {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var with []string
+		for _, k := range keys {
+			with = append(with, k+"="+c.With[k])
+		}
+		fmt.Printf("     with: %s\n", strings.Join(with, ", "))
+	}
+}
+
+func init() {
+	usageCmd.Flags().StringVar(&catalogPath, "catalog", ".stringercache.json", "Action catalog to resolve against (scan cache or --output file)")
+	usageCmd.Flags().BoolVar(&usageJSON, "json", false, "Print the usage report as JSON")
+	usageCmd.Flags().StringVar(&usageAction, "action", "", "Only report actions whose name or uses reference contains this text")
+	usageCmd.Flags().BoolVar(&showUnresolved, "unresolved", false, "Also list calls to actions that are not in the catalog")
+	addGithubFlags(usageCmd)
+	rootCmd.AddCommand(usageCmd)
+}
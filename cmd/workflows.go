@@ -0,0 +1,98 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tnaucoin/stringer/parser"
+)
+
+var workflowsJSON bool
+
+// workflowsCmd represents the workflows command
+var workflowsCmd = &cobra.Command{
+	Use:   "workflows [path]",
+	Short: "list the Github workflows in a directory and the jobs they run",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
+		}
+		workflows, err := parser.ParseWorkflows(root)
+		if err != nil {
+			fmt.Println("Error: ", err)
+			os.Exit(1)
+		}
+
+		if workflowsJSON {
+			data, err := json.MarshalIndent(workflows, "", "	")
+			if err != nil {
+				fmt.Println("Failed to marshal workflows:", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		if len(workflows) == 0 {
+			fmt.Println("No workflows found")
+			return
+		}
+
+		for _, w := range workflows {
+			name := w.Name
+			if name == "" {
+				name = w.Path
+			}
+			fmt.Printf("📄 %s (%s)\n", name, w.Path)
+			fmt.Printf("   On: %s\n", strings.Join(w.Events(), ", "))
+			for _, job := range w.Jobs {
+				if job.Uses != "" {
+					fmt.Printf("   • %s → %s\n", job.ID, job.Uses)
+				} else {
+					fmt.Printf("   • %s (runs-on: %s, %d steps)\n", job.ID, strings.Join(job.RunsOn, ", "), len(job.Steps))
+				}
+				if len(job.Needs) > 0 {
+					fmt.Printf("     needs: %s\n", strings.Join(job.Needs, ", "))
+				}
+				if job.Strategy != nil && job.Strategy.Matrix != nil {
+					fmt.Printf("     matrix: %s\n", matrixAxes(job.Strategy.Matrix))
+				}
+				for _, step := range job.Steps {
+					if step.Uses != "" {
+						fmt.Printf("     uses: %s\n", step.Uses)
+					}
+				}
+			}
+			fmt.Println()
+		}
+	},
+}
+
+// matrixAxes summarizes a matrix by its axis names, or returns the
+// expression a dynamic matrix is built from
+func matrixAxes(matrix any) string {
+	m, ok := matrix.(map[string]any)
+	if !ok {
+		return fmt.Sprint(matrix)
+	}
+	var axes []string
+	for axis := range m {
+		axes = append(axes, axis)
+	}
+	sort.Strings(axes)
+	return strings.Join(axes, ", ")
+}
+
+func init() {
+	workflowsCmd.Flags().BoolVar(&workflowsJSON, "json", false, "Print the parsed workflows as JSON")
+	rootCmd.AddCommand(workflowsCmd)
+}
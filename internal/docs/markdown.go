@@ -0,0 +1,175 @@
+// Package docs renders action documentation as Markdown
+package docs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+// StartMarker and EndMarker delimit the generated region of a README,
+// everything outside of them is left alone
+const (
+	StartMarker = "<!-- stringer:start -->"
+	EndMarker   = "<!-- stringer:end -->"
+)
+
+var ErrNoMarkers = errors.New("no " + StartMarker + " / " + EndMarker + " region found")
+
+// Markdown renders the documentation of a single action. uses is the
+// reference shown in the usage snippet, empty falls back to the action's
+// provenance.
+func Markdown(action types.CompositeAction, uses string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", action.Name)
+	if action.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(action.Description))
+	}
+
+	if len(action.Inputs) > 0 {
+		b.WriteString("### Inputs\n\n")
+		b.WriteString("| Name | Description | Required | Default |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, in := range action.Inputs {
+			description := in.Description
+			if in.DeprecationMessage != "" {
+				description = strings.TrimSpace("**Deprecated:** " + in.DeprecationMessage + " " + description)
+			}
+			required := "no"
+			if in.Required {
+				required = "yes"
+			}
+			def := ""
+			if in.Default != "" {
+				def = codeSpan(cell(in.Default))
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", in.Name, cell(description), required, def)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(action.Outputs) > 0 {
+		b.WriteString("### Outputs\n\n")
+		b.WriteString("| Name | Description |\n")
+		b.WriteString("| --- | --- |\n")
+		for _, out := range action.Outputs {
+			fmt.Fprintf(&b, "| `%s` | %s |\n", out.Name, cell(out.Description))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("### Usage\n\n")
+	b.WriteString("```yaml\n")
+	b.WriteString(UsageSnippet(action, uses))
+	b.WriteString("```\n")
+	return b.String()
+}
+
+// MarkdownAll renders every action, one section after the other
+func MarkdownAll(actions []types.CompositeAction, uses func(types.CompositeAction) string) string {
+	sections := make([]string, 0, len(actions))
+	for _, a := range actions {
+		sections = append(sections, Markdown(a, uses(a)))
+	}
+	return strings.Join(sections, "\n")
+}
+
+// UsageSnippet is a step calling the action with every input, required
+// inputs come first
+func UsageSnippet(action types.CompositeAction, uses string) string {
+	if uses == "" {
+		uses = action.Provenance.Uses
+	}
+	if uses == "" {
+		uses = action.Provenance.Path
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "- uses: %s\n", uses)
+	if len(action.Inputs) == 0 {
+		return b.String()
+	}
+	b.WriteString("  with:\n")
+	for _, required := range []bool{true, false} {
+		for _, in := range action.Inputs {
+			if in.Required != required || in.DeprecationMessage != "" {
+				continue
+			}
+			value := `""`
+			if in.Default != "" {
+				value = quote(in.Default)
+			}
+			if in.Description != "" {
+				fmt.Fprintf(&b, "    # %s\n", strings.ReplaceAll(strings.TrimSpace(in.Description), "\n", "\n    # "))
+			}
+			fmt.Fprintf(&b, "    %s: %s\n", in.Name, value)
+		}
+	}
+	return b.String()
+}
+
+// UpdateRegion replaces the text between the markers in content with
+// generated and returns the new content
+func UpdateRegion(content, generated string) (string, error) {
+	start := strings.Index(content, StartMarker)
+	if start < 0 {
+		return "", ErrNoMarkers
+	}
+	end := strings.Index(content[start:], EndMarker)
+	if end < 0 {
+		return "", ErrNoMarkers
+	}
+	end += start
+
+	var b strings.Builder
+	b.WriteString(content[:start+len(StartMarker)])
+	b.WriteString("\n\n")
+	b.WriteString(strings.TrimSpace(generated))
+	b.WriteString("\n\n")
+	b.WriteString(content[end:])
+	return b.String(), nil
+}
+
+// Region wraps generated in the markers, for READMEs that don't exist yet
+func Region(generated string) string {
+	return StartMarker + "\n\n" + strings.TrimSpace(generated) + "\n\n" + EndMarker + "\n"
+}
+
+// cell makes text safe for a table cell
+func cell(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// codeSpan wraps s in a code span, fenced by one more backtick than the
+// longest run s contains so backticks in it don't end the span
+func codeSpan(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", longest+1)
+	if longest > 0 {
+		// a space keeps backticks at the edges apart from the fence
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// quote returns s as a YAML scalar, quoting only when it would not be read
+// back as the same string
+func quote(s string) string {
+	if strings.ContainsAny(s, ":#{}[]&*!|>'\"%@`,\n") || strings.TrimSpace(s) != s {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+package docs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+func TestMarkdown(t *testing.T) {
+	action := types.CompositeAction{
+		Name:        "Deploy",
+		Description: "Deploys a service",
+		Inputs: types.ActionInputs{
+			{Name: "region", Description: "AWS region", Default: "us-east-1"},
+			{Name: "service", Description: "Service | name", Required: true},
+			{Name: "zone", DeprecationMessage: "use region"},
+		},
+		Outputs: types.ActionOutputs{
+			{Name: "url", Description: "Deployed URL"},
+		},
+		Provenance: types.Provenance{Uses: "./deploy"},
+	}
+
+	expected := "## Deploy\n\n" +
+		"Deploys a service\n\n" +
+		"### Inputs\n\n" +
+		"| Name | Description | Required | Default |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| `region` | AWS region | no | `us-east-1` |\n" +
+		"| `service` | Service \\| name | yes |  |\n" +
+		"| `zone` | **Deprecated:** use region | no |  |\n\n" +
+		"### Outputs\n\n" +
+		"| Name | Description |\n" +
+		"| --- | --- |\n" +
+		"| `url` | Deployed URL |\n\n" +
+		"### Usage\n\n" +
+		"```yaml\n" +
+		"- uses: my-org/actions/deploy@v1\n" +
+		"  with:\n" +
+		"    # Service | name\n" +
+		"    service: \"\"\n" +
+		"    # AWS region\n" +
+		"    region: us-east-1\n" +
+		"```\n"
+
+	if got := Markdown(action, "my-org/actions/deploy@v1"); got != expected {
+		t.Errorf("unexpected markdown:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	if got := Markdown(types.CompositeAction{Name: "x", Provenance: types.Provenance{Uses: "./x"}}, ""); got != "## x\n\n### Usage\n\n```yaml\n- uses: ./x\n```\n" {
+		t.Errorf("expected provenance uses without inputs, got:\n%s", got)
+	}
+}
+
+func TestMarkdownDefaults(t *testing.T) {
+	tests := []struct {
+		def      string
+		expected string
+	}{
+		{"us-east-1", "| `us-east-1` |"},
+		{"a|b", "| `a\\|b` |"},
+		{"`date`", "| `` `date` `` |"},
+		{"x``y", "| ``` x``y ``` |"},
+	}
+	for _, tt := range tests {
+		action := types.CompositeAction{Name: "x", Inputs: types.ActionInputs{{Name: "in", Default: tt.def}}}
+		if got := Markdown(action, "./x"); !strings.Contains(got, "| `in` |  | no "+tt.expected+"\n") {
+			t.Errorf("expected default %q to render as %s, got:\n%s", tt.def, tt.expected, got)
+		}
+	}
+}
+
+func TestUpdateRegion(t *testing.T) {
+	readme := "# Actions\n\nIntro.\n\n" + StartMarker + "\nold docs\n" + EndMarker + "\n\nFooter.\n"
+	expected := "# Actions\n\nIntro.\n\n" + StartMarker + "\n\n## new\n\n" + EndMarker + "\n\nFooter.\n"
+
+	got, err := UpdateRegion(readme, "## new\n")
+	if err != nil {
+		t.Fatalf("UpdateRegion returned error: %v", err)
+	}
+	if got != expected {
+		t.Errorf("unexpected README:\n%s", got)
+	}
+	// updating again with the same docs is a no-op
+	if again, _ := UpdateRegion(got, "## new\n"); again != got {
+		t.Errorf("expected UpdateRegion to be idempotent, got:\n%s", again)
+	}
+
+	for _, content := range []string{"# Actions\n", EndMarker + "\n" + StartMarker + "\n"} {
+		if _, err := UpdateRegion(content, "x"); !errors.Is(err, ErrNoMarkers) {
+			t.Errorf("expected ErrNoMarkers for %q, got %v", content, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package expr
+
+// Node is an expression AST node
+type Node interface {
+	Position() int
+}
+
+type LiteralKind int
+
+const (
+	LiteralNull LiteralKind = iota
+	LiteralBool
+	LiteralNumber
+	LiteralString
+)
+
+// Literal is null, true, false, a number or a string. Value holds nil, a
+// bool, a float64 or a string respectively.
+type Literal struct {
+	Pos   int
+	Kind  LiteralKind
+	Value any
+}
+
+// Ident is a context name such as github, inputs or steps
+type Ident struct {
+	Pos  int
+	Name string
+}
+
+// Property is a dereference, Name is "*" for an object filter like a.*
+type Property struct {
+	Pos    int
+	Target Node
+	Name   string
+}
+
+// Index is an index access like a['b'] or a[0], Index is a *Star for a[*]
+type Index struct {
+	Pos    int
+	Target Node
+	Index  Node
+}
+
+// Star is the `*` of an array filter
+type Star struct {
+	Pos int
+}
+
+// Call is a function call, Name keeps the spelling used in the source
+type Call struct {
+	Pos  int
+	Name string
+	Args []Node
+}
+
+// Unary is the logical not operator
+type Unary struct {
+	Pos     int
+	Op      string
+	Operand Node
+}
+
+// Binary is a comparison or logical operator
+type Binary struct {
+	Pos   int
+	Op    string
+	Left  Node
+	Right Node
+}
+
+func (n *Literal) Position() int  { return n.Pos }
+func (n *Ident) Position() int    { return n.Pos }
+func (n *Property) Position() int { return n.Pos }
+func (n *Index) Position() int    { return n.Pos }
+func (n *Star) Position() int     { return n.Pos }
+func (n *Call) Position() int     { return n.Pos }
+func (n *Unary) Position() int    { return n.Pos }
+func (n *Binary) Position() int   { return n.Pos }
@@ -0,0 +1,162 @@
+// Package expr parses GitHub Actions expressions, the `${{ }}` syntax used
+// in action and workflow files
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type TokenKind int
+
+const (
+	TokenEOF TokenKind = iota
+	TokenIdent
+	TokenNumber
+	TokenString
+	TokenOperator
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenIdent:
+		return "identifier"
+	case TokenNumber:
+		return "number"
+	case TokenString:
+		return "string"
+	case TokenOperator:
+		return "operator"
+	}
+	return "end of expression"
+}
+
+type Token struct {
+	Kind TokenKind
+	// Value is the literal text for identifiers and operators, the
+	// unescaped contents for strings
+	Value string
+	Pos   int
+}
+
+// SyntaxError reports a malformed expression, Pos is the byte offset in
+// the parsed source
+type SyntaxError struct {
+	Pos     int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Message)
+}
+
+// operators is ordered so two character operators are matched first
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "!", "<", ">", "(", ")", "[", "]", ".", ",", "*"}
+
+// Lex splits an expression into tokens, the last token is always TokenEOF
+func Lex(src string) ([]Token, error) {
+	var tokens []Token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			s, n, err := lexString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, Token{Kind: TokenString, Value: s, Pos: i})
+			i += n
+		case isDigit(c) || (c == '-' && i+1 < len(src) && (isDigit(src[i+1]) || src[i+1] == '.')):
+			n, err := lexNumber(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, Token{Kind: TokenNumber, Value: src[i : i+n], Pos: i})
+			i += n
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(src) && isIdentChar(src[j]) {
+				j++
+			}
+			tokens = append(tokens, Token{Kind: TokenIdent, Value: src[i:j], Pos: i})
+			i = j
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, &SyntaxError{Pos: i, Message: fmt.Sprintf("unexpected character %q", c)}
+			}
+			tokens = append(tokens, Token{Kind: TokenOperator, Value: op, Pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, Token{Kind: TokenEOF, Pos: len(src)}), nil
+}
+
+// lexString reads a single quoted string starting at src[start], quotes
+// are escaped by doubling them
+func lexString(src string, start int) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		if src[i] != '\'' {
+			b.WriteByte(src[i])
+			continue
+		}
+		if i+1 < len(src) && src[i+1] == '\'' {
+			b.WriteByte('\'')
+			i++
+			continue
+		}
+		return b.String(), i - start + 1, nil
+	}
+	return "", 0, &SyntaxError{Pos: start, Message: "unterminated string"}
+}
+
+// lexNumber reads an integer, hexadecimal, float or exponent literal
+func lexNumber(src string, start int) (int, error) {
+	i := start
+	if src[i] == '-' {
+		i++
+	}
+	for i < len(src) {
+		c := src[i]
+		if isIdentChar(c) && c != '-' || c == '.' {
+			i++
+			continue
+		}
+		// the sign of an exponent
+		if (c == '+' || c == '-') && (src[i-1] == 'e' || src[i-1] == 'E') && !strings.HasPrefix(strings.TrimPrefix(src[start:], "-"), "0x") {
+			i++
+			continue
+		}
+		break
+	}
+	text := src[start:i]
+	if _, err := strconv.ParseFloat(text, 64); err != nil {
+		if _, err := strconv.ParseInt(text, 0, 64); err != nil {
+			return 0, &SyntaxError{Pos: start, Message: fmt.Sprintf("invalid number %q", text)}
+		}
+	}
+	return i - start, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isIdentChar allows dashes, which are common in step ids and input names
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '-'
+}
@@ -0,0 +1,255 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// arity is the number of arguments a function accepts, max -1 is variadic
+type arity struct{ min, max int }
+
+// functions are the builtin functions, names are case insensitive
+var functions = map[string]arity{
+	"contains":   {2, 2},
+	"startswith": {2, 2},
+	"endswith":   {2, 2},
+	"format":     {1, -1},
+	"join":       {1, 2},
+	"tojson":     {1, 1},
+	"fromjson":   {1, 1},
+	"hashfiles":  {1, -1},
+	"success":    {0, 0},
+	"always":     {0, 0},
+	"cancelled":  {0, 0},
+	"failure":    {0, 0},
+}
+
+// Parse parses a bare expression, the text between `${{` and `}}`
+func Parse(src string) (Node, error) {
+	tokens, err := Lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().Kind == TokenEOF {
+		return nil, &SyntaxError{Pos: 0, Message: "empty expression"}
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.Kind != TokenEOF {
+		return nil, unexpected(tok)
+	}
+	return node, nil
+}
+
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() Token {
+	tok := p.tokens[p.pos]
+	if tok.Kind != TokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is one of the given operators
+func (p *parser) accept(ops ...string) (Token, bool) {
+	tok := p.peek()
+	if tok.Kind != TokenOperator {
+		return tok, false
+	}
+	for _, op := range ops {
+		if tok.Value == op {
+			return p.next(), true
+		}
+	}
+	return tok, false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		return unexpected(p.peek())
+	}
+	return nil
+}
+
+// parseBinary parses a left associative chain of ops over operands
+func (p *parser) parseBinary(operand func() (Node, error), ops ...string) (Node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.accept(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Pos: tok.Pos, Op: tok.Value, Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseOr() (Node, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	return p.parseBinary(p.parseEquality, "&&")
+}
+
+func (p *parser) parseEquality() (Node, error) {
+	return p.parseBinary(p.parseComparison, "==", "!=")
+}
+
+func (p *parser) parseComparison() (Node, error) {
+	return p.parseBinary(p.parseUnary, "<", "<=", ">", ">=")
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if tok, ok := p.accept("!"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Pos: tok.Pos, Op: tok.Value, Operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix parses a primary followed by any property or index access
+func (p *parser) parsePostfix() (Node, error) {
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if tok, ok := p.accept("."); ok {
+			name := p.next()
+			switch {
+			case name.Kind == TokenIdent:
+				node = &Property{Pos: tok.Pos, Target: node, Name: name.Value}
+			case name.Kind == TokenOperator && name.Value == "*":
+				node = &Property{Pos: tok.Pos, Target: node, Name: "*"}
+			default:
+				return nil, unexpected(name)
+			}
+			continue
+		}
+		if tok, ok := p.accept("["); ok {
+			var index Node
+			if star, ok := p.accept("*"); ok {
+				index = &Star{Pos: star.Pos}
+			} else if index, err = p.parseOr(); err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			node = &Index{Pos: tok.Pos, Target: node, Index: index}
+			continue
+		}
+		return node, nil
+	}
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.Kind {
+	case TokenString:
+		return &Literal{Pos: tok.Pos, Kind: LiteralString, Value: tok.Value}, nil
+	case TokenNumber:
+		return &Literal{Pos: tok.Pos, Kind: LiteralNumber, Value: parseNumber(tok.Value)}, nil
+	case TokenIdent:
+		switch tok.Value {
+		case "true", "false":
+			return &Literal{Pos: tok.Pos, Kind: LiteralBool, Value: tok.Value == "true"}, nil
+		case "null":
+			return &Literal{Pos: tok.Pos, Kind: LiteralNull}, nil
+		}
+		if _, ok := p.accept("("); ok {
+			return p.parseCall(tok)
+		}
+		return &Ident{Pos: tok.Pos, Name: tok.Value}, nil
+	case TokenOperator:
+		if tok.Value == "(" {
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return node, nil
+		}
+	}
+	return nil, unexpected(tok)
+}
+
+// parseCall parses the arguments of a call to name, the opening paren is
+// already consumed
+func (p *parser) parseCall(name Token) (Node, error) {
+	call := &Call{Pos: name.Pos, Name: name.Value}
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			call.Args = append(call.Args, arg)
+			if _, ok := p.accept(","); !ok {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	a, ok := functions[strings.ToLower(call.Name)]
+	if !ok {
+		return nil, &SyntaxError{Pos: name.Pos, Message: fmt.Sprintf("unknown function %q", call.Name)}
+	}
+	if len(call.Args) < a.min || (a.max >= 0 && len(call.Args) > a.max) {
+		return nil, &SyntaxError{Pos: name.Pos, Message: fmt.Sprintf("%s takes %s, got %d", call.Name, a, len(call.Args))}
+	}
+	return call, nil
+}
+
+func (a arity) String() string {
+	switch {
+	case a.max < 0:
+		return fmt.Sprintf("at least %d arguments", a.min)
+	case a.min == a.max:
+		return fmt.Sprintf("%d arguments", a.min)
+	}
+	return fmt.Sprintf("%d to %d arguments", a.min, a.max)
+}
+
+// parseNumber converts a lexed number, hexadecimal literals are integers
+func parseNumber(text string) float64 {
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f
+	}
+	i, _ := strconv.ParseInt(text, 0, 64)
+	return float64(i)
+}
+
+func unexpected(tok Token) error {
+	if tok.Kind == TokenEOF {
+		return &SyntaxError{Pos: tok.Pos, Message: "unexpected end of expression"}
+	}
+	return &SyntaxError{Pos: tok.Pos, Message: fmt.Sprintf("unexpected %s %q", tok.Kind, tok.Value)}
+}
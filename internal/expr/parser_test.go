@@ -0,0 +1,111 @@
+package expr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	tokens, err := Lex("steps.set-greeting.outputs['x'] != 'it''s' && -1.5e-3 >= 0xff")
+	if err != nil {
+		t.Fatalf("Lex returned error: %v", err)
+	}
+	var got []string
+	for _, tok := range tokens {
+		got = append(got, tok.Value)
+	}
+	expected := []string{"steps", ".", "set-greeting", ".", "outputs", "[", "x", "]", "!=", "it's", "&&", "-1.5e-3", ">=", "0xff", ""}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected Node
+	}{
+		{
+			name: "property access",
+			src:  "inputs.name",
+			expected: &Property{Pos: 6, Name: "name",
+				Target: &Ident{Pos: 0, Name: "inputs"}},
+		},
+		{
+			name: "precedence",
+			src:  "!a || b == 'x' && c < 1",
+			expected: &Binary{Pos: 3, Op: "||",
+				Left: &Unary{Pos: 0, Op: "!", Operand: &Ident{Pos: 1, Name: "a"}},
+				Right: &Binary{Pos: 15, Op: "&&",
+					Left: &Binary{Pos: 8, Op: "==",
+						Left:  &Ident{Pos: 6, Name: "b"},
+						Right: &Literal{Pos: 11, Kind: LiteralString, Value: "x"}},
+					Right: &Binary{Pos: 20, Op: "<",
+						Left:  &Ident{Pos: 18, Name: "c"},
+						Right: &Literal{Pos: 22, Kind: LiteralNumber, Value: 1.0}}}},
+		},
+		{
+			name: "function call and filter",
+			src:  "contains(github.event.*.labels[*], null)",
+			expected: &Call{Pos: 0, Name: "contains", Args: []Node{
+				&Index{Pos: 30, Index: &Star{Pos: 31},
+					Target: &Property{Pos: 23, Name: "labels",
+						Target: &Property{Pos: 21, Name: "*",
+							Target: &Property{Pos: 15, Name: "event",
+								Target: &Ident{Pos: 9, Name: "github"}}}}},
+				&Literal{Pos: 35, Kind: LiteralNull},
+			}},
+		},
+		{
+			name: "grouping and literals",
+			src:  "(true) != fromJSON('0x10')",
+			expected: &Binary{Pos: 7, Op: "!=",
+				Left: &Literal{Pos: 1, Kind: LiteralBool, Value: true},
+				Right: &Call{Pos: 10, Name: "fromJSON", Args: []Node{
+					&Literal{Pos: 19, Kind: LiteralString, Value: "0x10"}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("unexpected AST for %q: %#v", tt.src, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		pos int
+	}{
+		{"", 0},
+		{"inputs.", 7},
+		{"'unterminated", 0},
+		{"a == == b", 5},
+		{"contains(a)", 0},
+		{"unknown(a)", 0},
+		{"a[1", 3},
+		{"a b", 2},
+		{"1.2.3", 0},
+		{"a # b", 2},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("Parse(%q): expected *SyntaxError, got %v", tt.src, err)
+			continue
+		}
+		if se.Pos != tt.pos {
+			t.Errorf("Parse(%q): expected error at %d, got %d (%s)", tt.src, tt.pos, se.Pos, se.Message)
+		}
+	}
+}
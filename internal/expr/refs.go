@@ -0,0 +1,110 @@
+package expr
+
+import "strings"
+
+// Contexts are the context names an expression can start from
+var Contexts = []string{
+	"github", "env", "vars", "job", "jobs", "steps", "runner",
+	"secrets", "strategy", "matrix", "needs", "inputs",
+}
+
+// IsContext reports whether name is a known context, contexts are case
+// insensitive
+func IsContext(name string) bool {
+	for _, c := range Contexts {
+		if strings.EqualFold(c, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reference is a context access such as steps.build.outputs.path
+type Reference struct {
+	// Context is lower cased, Path holds the property names that follow
+	// it up to the first dynamic index or filter
+	Context string
+	Path    []string
+	Pos     int
+}
+
+// String renders the reference in dotted form
+func (r Reference) String() string {
+	return strings.Join(append([]string{r.Context}, r.Path...), ".")
+}
+
+// References returns every context access in the expression in source
+// order. Literal string indexes count as properties, so steps['build']
+// and steps.build are the same reference.
+func References(n Node) []Reference {
+	var refs []Reference
+	collect(n, &refs)
+	return refs
+}
+
+// TemplateReferences is References over every block of a template
+func TemplateReferences(exprs []Expression) []Reference {
+	var refs []Reference
+	for _, e := range exprs {
+		for _, r := range References(e.Node) {
+			r.Pos += e.Offset
+			refs = append(refs, r)
+		}
+	}
+	return refs
+}
+
+func collect(n Node, refs *[]Reference) {
+	switch n := n.(type) {
+	case *Ident:
+		*refs = append(*refs, Reference{Context: strings.ToLower(n.Name), Pos: n.Pos})
+	case *Property, *Index:
+		collectAccess(n, refs)
+	case *Call:
+		for _, arg := range n.Args {
+			collect(arg, refs)
+		}
+	case *Unary:
+		collect(n.Operand, refs)
+	case *Binary:
+		collect(n.Left, refs)
+		collect(n.Right, refs)
+	}
+}
+
+// collectAccess walks a chain of property and index accesses down to its
+// root, keeping only the part of the path before a dynamic index
+func collectAccess(n Node, refs *[]Reference) {
+	var path []string
+	var dynamic []Node
+	for {
+		switch c := n.(type) {
+		case *Property:
+			if c.Name == "*" {
+				path = nil
+			} else {
+				path = append([]string{c.Name}, path...)
+			}
+			n = c.Target
+			continue
+		case *Index:
+			if lit, ok := c.Index.(*Literal); ok && lit.Kind == LiteralString {
+				path = append([]string{lit.Value.(string)}, path...)
+			} else {
+				path = nil
+				dynamic = append(dynamic, c.Index)
+			}
+			n = c.Target
+			continue
+		case *Ident:
+			*refs = append(*refs, Reference{Context: strings.ToLower(c.Name), Path: path, Pos: c.Pos})
+		default:
+			collect(n, refs)
+		}
+		break
+	}
+	// dynamic indexes were found outermost first
+	for i := len(dynamic) - 1; i >= 0; i-- {
+		collect(dynamic[i], refs)
+	}
+}
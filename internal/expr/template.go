@@ -0,0 +1,82 @@
+package expr
+
+import (
+	"errors"
+	"strings"
+)
+
+// Expression is one `${{ }}` block of a template string
+type Expression struct {
+	// Source is the text between the delimiters, Offset its byte offset in
+	// the template
+	Source string
+	Offset int
+	Node   Node
+}
+
+// ParseTemplate parses every `${{ }}` block embedded in s. Positions in
+// returned syntax errors are offsets into s.
+func ParseTemplate(s string) ([]Expression, error) {
+	var exprs []Expression
+	for i := 0; ; {
+		start := strings.Index(s[i:], "${{")
+		if start < 0 {
+			return exprs, nil
+		}
+		start += i + len("${{")
+		end, err := closingDelimiter(s, start)
+		if err != nil {
+			return nil, err
+		}
+		src := s[start:end]
+		node, err := Parse(src)
+		if err != nil {
+			return nil, shift(err, start)
+		}
+		exprs = append(exprs, Expression{Source: src, Offset: start, Node: node})
+		i = end + len("}}")
+	}
+}
+
+// ParseCondition parses an `if:` value, which may be a bare expression or
+// wrapped in `${{ }}`
+func ParseCondition(s string) (Node, error) {
+	if strings.HasPrefix(strings.TrimSpace(s), "${{") {
+		start := strings.Index(s, "${{") + len("${{")
+		end, err := closingDelimiter(s, start)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(s[end+len("}}"):]) == "" {
+			node, err := Parse(s[start:end])
+			if err != nil {
+				return nil, shift(err, start)
+			}
+			return node, nil
+		}
+	}
+	return Parse(s)
+}
+
+// closingDelimiter finds the `}}` closing the block starting at start,
+// braces inside string literals don't count
+func closingDelimiter(s string, start int) (int, error) {
+	inString := false
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '\'':
+			inString = !inString
+		case !inString && strings.HasPrefix(s[i:], "}}"):
+			return i, nil
+		}
+	}
+	return 0, &SyntaxError{Pos: start - len("${{"), Message: "unclosed ${{"}
+}
+
+func shift(err error, offset int) error {
+	var se *SyntaxError
+	if errors.As(err, &se) {
+		return &SyntaxError{Pos: se.Pos + offset, Message: se.Message}
+	}
+	return err
+}
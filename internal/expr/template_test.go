@@ -0,0 +1,85 @@
+package expr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+	exprs, err := ParseTemplate("echo ${{ inputs.name }} ${{ format('{0}}', steps.a.outputs.b) }}")
+	if err != nil {
+		t.Fatalf("ParseTemplate returned error: %v", err)
+	}
+	if len(exprs) != 2 {
+		t.Fatalf("expected 2 expressions, got %d", len(exprs))
+	}
+	if exprs[0].Source != " inputs.name " || exprs[0].Offset != 8 {
+		t.Errorf("unexpected first expression %+v", exprs[0])
+	}
+	if exprs[1].Source != " format('{0}}', steps.a.outputs.b) " {
+		t.Errorf("braces in strings should not close the block, got %q", exprs[1].Source)
+	}
+
+	refs := TemplateReferences(exprs)
+	var got []string
+	for _, r := range refs {
+		got = append(got, r.String())
+	}
+	expected := []string{"inputs.name", "steps.a.outputs.b"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected references %v, got %v", expected, got)
+	}
+	if refs[0].Pos != 9 {
+		t.Errorf("expected reference position 9, got %d", refs[0].Pos)
+	}
+}
+
+func TestParseTemplateErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		pos int
+	}{
+		{"echo ${{ inputs.name", 5},
+		{"echo ${{ inputs. }}", 17},
+	}
+	for _, tt := range tests {
+		_, err := ParseTemplate(tt.src)
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("ParseTemplate(%q): expected *SyntaxError, got %v", tt.src, err)
+			continue
+		}
+		if se.Pos != tt.pos {
+			t.Errorf("ParseTemplate(%q): expected error at %d, got %d", tt.src, tt.pos, se.Pos)
+		}
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	for _, src := range []string{"success() && inputs.deploy", "${{ success() && inputs.deploy }}", "  ${{ success() && inputs.deploy }}\n"} {
+		node, err := ParseCondition(src)
+		if err != nil {
+			t.Errorf("ParseCondition(%q) returned error: %v", src, err)
+			continue
+		}
+		if b, ok := node.(*Binary); !ok || b.Op != "&&" {
+			t.Errorf("ParseCondition(%q): expected && expression, got %#v", src, node)
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	node, err := Parse("Steps['build'].outputs[matrix.os] || needs.*.result || fromJSON(env.LIST)[0].name || github")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	var got []string
+	for _, r := range References(node) {
+		got = append(got, r.String())
+	}
+	expected := []string{"steps.build.outputs", "matrix.os", "needs", "env.LIST", "github"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
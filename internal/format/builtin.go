@@ -0,0 +1,165 @@
+package format
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/tnaucoin/stringer/internal/docs"
+	"github.com/tnaucoin/stringer/types"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	Text     = "text"
+	Table    = "table"
+	JSON     = "json"
+	YAML     = "yaml"
+	CSV      = "csv"
+	Markdown = "markdown"
+	Template = "template"
+)
+
+func init() {
+	Register(Text, static(writeText))
+	Register(Table, static(writeTable))
+	Register(JSON, static(writeJSON))
+	Register(YAML, static(writeYAML))
+	Register(CSV, static(writeCSV))
+	Register(Markdown, static(writeMarkdown))
+	Register(Template, newTemplate)
+}
+
+// writeText is the human readable listing scan has always printed
+func writeText(w io.Writer, actions []types.CompositeAction) error {
+	if len(actions) == 0 {
+		_, err := fmt.Fprintln(w, "No actions found")
+		return err
+	}
+	for _, a := range actions {
+		fmt.Fprintf(w, "🔹 %s — %s\n", a.Name, a.Description)
+		fmt.Fprintf(w, "   Kind: %s (%s)\n", a.Kind, a.Runs.Using)
+		if a.Provenance.Uses != "" {
+			fmt.Fprintf(w, "   Uses: %s\n", a.Provenance.Uses)
+		}
+		if calls := a.Calls(); len(calls) > 0 {
+			fmt.Fprintf(w, "   Calls: %s\n", strings.Join(calls, ", "))
+		}
+		fmt.Fprintf(w, "   Inputs: %s\n", InputNames(a.Inputs))
+		if _, err := fmt.Fprintf(w, "   Outputs: %s\n\n", OutputNames(a.Outputs)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTable(w io.Writer, actions []types.CompositeAction) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tKIND\tUSES\tINPUTS\tOUTPUTS")
+	for _, a := range actions {
+		uses := a.Provenance.Uses
+		if uses == "" {
+			uses = a.Provenance.Path
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", a.Name, a.Kind, uses, InputNames(a.Inputs), OutputNames(a.Outputs))
+	}
+	return tw.Flush()
+}
+
+func writeJSON(w io.Writer, actions []types.CompositeAction) error {
+	if actions == nil {
+		actions = []types.CompositeAction{}
+	}
+	data, err := json.MarshalIndent(actions, "", "	")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func writeYAML(w io.Writer, actions []types.CompositeAction) error {
+	if actions == nil {
+		actions = []types.CompositeAction{}
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(actions); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// writeCSV writes one row per action, inputs and outputs are joined with
+// semicolons
+func writeCSV(w io.Writer, actions []types.CompositeAction) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"name", "kind", "using", "uses", "path", "description", "inputs", "required_inputs", "outputs"})
+	for _, a := range actions {
+		var inputs, required, outputs []string
+		for _, in := range a.Inputs {
+			inputs = append(inputs, in.Name)
+			if in.Required {
+				required = append(required, in.Name)
+			}
+		}
+		for _, out := range a.Outputs {
+			outputs = append(outputs, out.Name)
+		}
+		cw.Write([]string{
+			a.Name, string(a.Kind), a.Runs.Using, a.Provenance.Uses, a.Provenance.Path, a.Description,
+			strings.Join(inputs, ";"), strings.Join(required, ";"), strings.Join(outputs, ";"),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeMarkdown(w io.Writer, actions []types.CompositeAction) error {
+	_, err := io.WriteString(w, docs.MarkdownAll(actions, func(types.CompositeAction) string { return "" }))
+	return err
+}
+
+// newTemplate builds the template format, the template is executed once
+// with the list of actions as its data
+func newTemplate(opts Options) (Formatter, error) {
+	if opts.Template == "" {
+		return nil, fmt.Errorf("the template format requires a template")
+	}
+	tmpl, err := template.New("scan").Funcs(template.FuncMap{
+		"join":        strings.Join,
+		"inputNames":  InputNames,
+		"outputNames": OutputNames,
+	}).Parse(opts.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return FormatterFunc(func(w io.Writer, actions []types.CompositeAction) error {
+		return tmpl.Execute(w, actions)
+	}), nil
+}
+
+// InputNames lists input names, required ones are marked
+func InputNames(inputs types.ActionInputs) string {
+	var names []string
+	for _, in := range inputs {
+		if in.Required {
+			names = append(names, in.Name+" (required)")
+		} else {
+			names = append(names, in.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func OutputNames(outputs types.ActionOutputs) string {
+	var names []string
+	for _, out := range outputs {
+		names = append(names, out.Name)
+	}
+	return strings.Join(names, ", ")
+}
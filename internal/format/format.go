@@ -0,0 +1,78 @@
+// Package format renders scanned actions in the output formats of
+// `stringer scan`. Formats register themselves by name, so adding one
+// doesn't require changes to the command.
+package format
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+// Formatter writes a list of actions to w
+type Formatter interface {
+	Format(w io.Writer, actions []types.CompositeAction) error
+}
+
+// FormatterFunc adapts a function to the Formatter interface
+type FormatterFunc func(w io.Writer, actions []types.CompositeAction) error
+
+func (f FormatterFunc) Format(w io.Writer, actions []types.CompositeAction) error {
+	return f(w, actions)
+}
+
+// Options configure formatters that need more than a name
+type Options struct {
+	// Template is the text/template source used by the template format
+	Template string
+}
+
+// Factory builds a formatter from the options
+type Factory func(opts Options) (Formatter, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register makes a format available under name, registering a name twice
+// replaces the earlier format
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[strings.ToLower(name)] = factory
+}
+
+// New returns the formatter registered under name
+func New(name string, opts Options) (Formatter, error) {
+	mu.RLock()
+	factory, ok := factories[strings.ToLower(name)]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+	return factory(opts)
+}
+
+// Names lists the registered formats
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// static registers a formatter that takes no options
+func static(f FormatterFunc) Factory {
+	return func(Options) (Formatter, error) {
+		return f, nil
+	}
+}
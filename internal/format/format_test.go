@@ -0,0 +1,115 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/tnaucoin/stringer/types"
+	"gopkg.in/yaml.v3"
+)
+
+var testActions = []types.CompositeAction{
+	{
+		Kind:        types.KindComposite,
+		Name:        "Deploy",
+		Description: "Deploys, a service",
+		Inputs:      types.ActionInputs{{Name: "service", Required: true, Line: 4}, {Name: "region"}},
+		Outputs:     types.ActionOutputs{{Name: "url"}},
+		Runs:        types.Runs{Using: "composite"},
+		Provenance:  types.Provenance{Kind: types.SourceLocal, Path: "deploy/action.yml", Uses: "./deploy"},
+	},
+}
+
+func render(t *testing.T, name string, opts Options, actions []types.CompositeAction) string {
+	t.Helper()
+	f, err := New(name, opts)
+	if err != nil {
+		t.Fatalf("New(%q) returned error: %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, actions); err != nil {
+		t.Fatalf("%s format returned error: %v", name, err)
+	}
+	return buf.String()
+}
+
+func TestBuiltinFormats(t *testing.T) {
+	tests := []struct {
+		format   string
+		contains []string
+	}{
+		{Text, []string{"🔹 Deploy — Deploys, a service", "Inputs: service (required), region"}},
+		{Table, []string{"NAME", "Deploy  composite  ./deploy  service (required), region  url"}},
+		{CSV, []string{"name,kind,using", `Deploy,composite,composite,./deploy,deploy/action.yml,"Deploys, a service",service;region,service,url`}},
+		{Markdown, []string{"## Deploy", "| `service` |  | yes |  |", "- uses: ./deploy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out := render(t, tt.format, Options{}, testActions)
+			for _, s := range tt.contains {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected output to contain %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestStructuredFormatsRoundTrip(t *testing.T) {
+	var fromJSON []types.CompositeAction
+	if err := json.Unmarshal([]byte(render(t, JSON, Options{}, testActions)), &fromJSON); err != nil {
+		t.Fatalf("json output doesn't decode: %v", err)
+	}
+	var fromYAML []types.CompositeAction
+	if err := yaml.Unmarshal([]byte(render(t, YAML, Options{}, testActions)), &fromYAML); err != nil {
+		t.Fatalf("yaml output doesn't decode: %v", err)
+	}
+	// json keeps positions like the cache does, yaml mirrors action.yml
+	lines := map[string]int{"json": 4, "yaml": 0}
+	for name, got := range map[string][]types.CompositeAction{"json": fromJSON, "yaml": fromYAML} {
+		if len(got) != 1 || got[0].Name != "Deploy" || got[0].Provenance.Uses != "./deploy" {
+			t.Errorf("%s: unexpected actions %+v", name, got)
+			continue
+		}
+		if in := got[0].Inputs[0]; in.Name != "service" || !in.Required || in.Line != lines[name] {
+			t.Errorf("%s: unexpected input %+v", name, in)
+		}
+	}
+
+	if out := render(t, JSON, Options{}, nil); strings.TrimSpace(out) != "[]" {
+		t.Errorf("expected an empty json list, got %q", out)
+	}
+}
+
+func TestTemplateFormat(t *testing.T) {
+	out := render(t, Template, Options{Template: `{{range .}}{{.Name}}={{inputNames .Inputs}}{{end}}`}, testActions)
+	if out != "Deploy=service (required), region" {
+		t.Errorf("unexpected template output %q", out)
+	}
+
+	if _, err := New(Template, Options{}); err == nil {
+		t.Error("expected an error without a template")
+	}
+	if _, err := New(Template, Options{Template: "{{ .Broken"}); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("names", static(func(w io.Writer, actions []types.CompositeAction) error {
+		for _, a := range actions {
+			io.WriteString(w, a.Name+"\n")
+		}
+		return nil
+	}))
+	if out := render(t, "NAMES", Options{}, testActions); out != "Deploy\n" {
+		t.Errorf("unexpected output from registered format %q", out)
+	}
+
+	if _, err := New("nope", Options{}); err == nil || !strings.Contains(err.Error(), "expected one of") {
+		t.Errorf("expected an unknown format error listing formats, got %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tnaucoin/stringer/internal/expr"
+	"github.com/tnaucoin/stringer/types"
+)
+
+const (
+	CodeInvalidExpression types.DiagnosticCode = "invalid-expression"
+	CodeUnknownStep       types.DiagnosticCode = "unknown-step"
+	CodeUnusedInput       types.DiagnosticCode = "unused-input"
+	CodeUndeclaredInput   types.DiagnosticCode = "undeclared-input"
+)
+
+// lineReference is a context reference and the line of the field it was
+// found in
+type lineReference struct {
+	expr.Reference
+	Line int
+}
+
+// CheckExpressions parses the `${{ }}` expressions of the workflows and
+// the composite actions cataloged from the checkout at root, and checks
+// that every steps.<id> they reference is a step declared earlier in the
+// same job or action. Actions of other roots and repos are not the caller's
+// to fix and are skipped.
+func CheckExpressions(catalog []types.CompositeAction, workflows []types.Workflow, root string) []types.Diagnostic {
+	var diags []types.Diagnostic
+	for _, w := range workflows {
+		file := w.Path
+		if w.Repo != "" {
+			file = w.Repo + ":" + file
+		}
+		for _, job := range w.Jobs {
+			d, _, _ := checkSteps(file, job.Steps)
+			diags = append(diags, d...)
+		}
+	}
+	for _, a := range catalog {
+		if a.Kind != types.KindComposite || a.Provenance.Kind != types.SourceLocal || a.Provenance.Root != root {
+			continue
+		}
+		diags = append(diags, CheckActionExpressions(actionFile(a), a)...)
+	}
+	Sort(diags)
+	return diags
+}
+
+// CheckActionExpressions checks the steps of a composite action and the
+// step outputs its outputs are mapped from, and that the inputs it
+// declares and the inputs its expressions use match up
+func CheckActionExpressions(file string, action types.CompositeAction) []types.Diagnostic {
+	diags, ids, used := checkSteps(file, action.Runs.Steps)
+	for _, out := range action.Outputs {
+		refs, d := parseField(file, out.Line, "output "+out.Name, out.Value, false)
+		diags = append(diags, d...)
+		for _, r := range refs {
+			used = append(used, lineReference{r, out.Line})
+			if id, ok := stepID(r); ok && !ids[strings.ToLower(id)] {
+				diags = append(diags, unknownStep(file, out.Line,
+					fmt.Sprintf("output %q references steps.%s, which is not a step of this action", out.Name, id)))
+			}
+		}
+	}
+	return append(diags, checkInputs(file, action.Inputs, used)...)
+}
+
+// checkInputs reports inputs.<name> references to undeclared inputs and
+// declared inputs no expression references. Accessing the whole inputs
+// context, e.g. toJSON(inputs), counts as using every input.
+func checkInputs(file string, inputs types.ActionInputs, refs []lineReference) []types.Diagnostic {
+	var diags []types.Diagnostic
+	used := map[string]bool{}
+	all := false
+	for _, r := range refs {
+		if r.Context != "inputs" {
+			continue
+		}
+		if len(r.Path) == 0 {
+			all = true
+			continue
+		}
+		used[strings.ToLower(r.Path[0])] = true
+		if _, ok := inputs.Get(r.Path[0]); !ok {
+			diags = append(diags, types.Diagnostic{
+				File:     file,
+				Line:     r.Line,
+				Severity: types.SeverityError,
+				Code:     CodeUndeclaredInput,
+				Message:  fmt.Sprintf("inputs.%s is not declared and is always empty%s", r.Path[0], suggestInput(inputs)),
+			})
+		}
+	}
+	if all {
+		return diags
+	}
+	for _, in := range inputs {
+		if !used[strings.ToLower(in.Name)] {
+			diags = append(diags, types.Diagnostic{
+				File:     file,
+				Line:     in.Line,
+				Severity: types.SeverityWarning,
+				Code:     CodeUnusedInput,
+				Message:  fmt.Sprintf("input %q is declared but never used", in.Name),
+			})
+		}
+	}
+	return diags
+}
+
+// checkSteps checks the expressions of a list of steps and returns the
+// lower cased ids they declare, step ids are case insensitive, and every
+// reference they make
+func checkSteps(file string, steps []types.Step) ([]types.Diagnostic, map[string]bool, []lineReference) {
+	var diags []types.Diagnostic
+	var all []lineReference
+	ids := map[string]bool{}
+	for i, step := range steps {
+		label := fmt.Sprintf("step %d", i+1)
+		if step.ID != "" {
+			label = fmt.Sprintf("step %q", step.ID)
+		}
+
+		var refs []expr.Reference
+		add := func(line int, field, value string, condition bool) {
+			if line == 0 {
+				line = step.Line
+			}
+			r, d := parseField(file, line, field, value, condition)
+			for _, ref := range r {
+				all = append(all, lineReference{ref, line})
+			}
+			refs = append(refs, r...)
+			diags = append(diags, d...)
+		}
+		add(step.Line, "if", step.If, true)
+		add(step.Line, "name", step.Name, false)
+		add(step.Line, "run", step.Run, false)
+		add(step.Line, "working-directory", step.WorkingDirectory, false)
+		add(step.Line, "continue-on-error", step.ContinueOnError, false)
+		for _, name := range sortedKeys(step.With) {
+			add(step.WithLines[name], "with."+name, step.With[name], false)
+		}
+		for _, name := range sortedKeys(step.Env) {
+			add(step.Line, "env."+name, step.Env[name], false)
+		}
+
+		for _, r := range refs {
+			if id, ok := stepID(r); ok && !ids[strings.ToLower(id)] {
+				diags = append(diags, unknownStep(file, step.Line,
+					fmt.Sprintf("%s references steps.%s, which is not declared before it", label, id)))
+			}
+		}
+		if step.ID != "" {
+			ids[strings.ToLower(step.ID)] = true
+		}
+	}
+	return diags, ids, all
+}
+
+// parseField parses the expressions in a field value and reports syntax
+// errors, conditions are expressions even without `${{ }}`
+func parseField(file string, line int, field, value string, condition bool) ([]expr.Reference, []types.Diagnostic) {
+	if value == "" {
+		return nil, nil
+	}
+	var refs []expr.Reference
+	var err error
+	if condition {
+		var node expr.Node
+		if node, err = expr.ParseCondition(value); err == nil {
+			refs = expr.References(node)
+		}
+	} else {
+		var exprs []expr.Expression
+		if exprs, err = expr.ParseTemplate(value); err == nil {
+			refs = expr.TemplateReferences(exprs)
+		}
+	}
+	if err != nil {
+		message := err.Error()
+		var se *expr.SyntaxError
+		if errors.As(err, &se) {
+			message = se.Message
+		}
+		return nil, []types.Diagnostic{{
+			File:     file,
+			Line:     line,
+			Severity: types.SeverityError,
+			Code:     CodeInvalidExpression,
+			Message:  fmt.Sprintf("invalid expression in %s: %s", field, message),
+		}}
+	}
+	return refs, nil
+}
+
+// stepID returns the step id of a steps.<id> reference
+func stepID(r expr.Reference) (string, bool) {
+	if r.Context != "steps" || len(r.Path) == 0 {
+		return "", false
+	}
+	return r.Path[0], true
+}
+
+func unknownStep(file string, line int, message string) types.Diagnostic {
+	return types.Diagnostic{
+		File:     file,
+		Line:     line,
+		Severity: types.SeverityError,
+		Code:     CodeUnknownStep,
+		Message:  message,
+	}
+}
+
+// actionFile is the file name diagnostics about an action are reported
+// against, remote actions are prefixed with their repository
+func actionFile(a types.CompositeAction) string {
+	if a.Provenance.Kind == types.SourceRemote {
+		return a.Provenance.Repo + ":" + actionPath(a)
+	}
+	return actionPath(a)
+}
+
+// actionPath is the path of an action's file. Local actions use the path
+// the tree was walked with, like workflows do, so both are relative to the
+// same directory; the repo relative path is the fallback for catalogs
+// loaded from a file, which don't store it.
+func actionPath(a types.CompositeAction) string {
+	if a.Provenance.Kind != types.SourceRemote && a.Path != "" {
+		return a.Path
+	}
+	return a.Provenance.Path
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,94 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+func TestCheckActionExpressions(t *testing.T) {
+	action := types.CompositeAction{
+		Kind: types.KindComposite,
+		Inputs: types.ActionInputs{
+			{Name: "Target", Line: 2},
+			{Name: "stale", Line: 4},
+		},
+		Runs: types.Runs{Steps: []types.Step{
+			{ID: "Build", Run: "make ${{ inputs.target }}", Line: 10},
+			{Run: "echo ${{ steps.build.outputs.path }} ${{ steps.test.outputs.report }}", Line: 13},
+			{ID: "test", If: "steps.build.outcome == 'success' &&", Line: 15},
+			{Run: "deploy ${{ inputs.environment }}", Line: 16},
+			{Uses: "actions/upload-artifact@v4", With: map[string]string{"path": "${{ steps.test.outputs.report }}", "name": "${{ inputs. }}"},
+				WithLines: map[string]int{"path": 20, "name": 21}, Line: 18},
+		}},
+		Outputs: types.ActionOutputs{
+			{Name: "path", Value: "${{ steps.build.outputs.path }}", Line: 3},
+			{Name: "coverage", Value: "${{ steps.coverage.outputs.percent }}", Line: 5},
+		},
+	}
+
+	type result struct {
+		Line int
+		Code types.DiagnosticCode
+	}
+	var got []result
+	for _, d := range CheckActionExpressions("action.yml", action) {
+		got = append(got, result{d.Line, d.Code})
+	}
+	expected := []result{
+		{13, CodeUnknownStep},
+		{15, CodeInvalidExpression},
+		{21, CodeInvalidExpression},
+		{5, CodeUnknownStep},
+		{16, CodeUndeclaredInput},
+		{4, CodeUnusedInput},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestCheckExpressionsWorkflows(t *testing.T) {
+	workflows := []types.Workflow{{
+		Path: ".github/workflows/ci.yml",
+		Repo: "my-org/app",
+		Jobs: []types.Job{
+			{ID: "a", Steps: []types.Step{{ID: "setup", Run: "true"}}},
+			// step ids are scoped to their job
+			{ID: "b", Steps: []types.Step{{Run: "echo ${{ steps.setup.outputs.dir }}", Line: 12}}},
+		},
+	}}
+	diags := CheckExpressions(nil, workflows, "")
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %+v", diags)
+	}
+	if diags[0].File != "my-org/app:.github/workflows/ci.yml" || diags[0].Code != CodeUnknownStep {
+		t.Errorf("unexpected diagnostic %+v", diags[0])
+	}
+}
+
+func TestCheckExpressionsRoot(t *testing.T) {
+	broken := func(name string, provenance types.Provenance) types.CompositeAction {
+		return types.CompositeAction{
+			Kind:       types.KindComposite,
+			Name:       name,
+			Path:       name + "/action.yml",
+			Runs:       types.Runs{Using: "composite", Steps: []types.Step{{Run: "echo ${{ steps.nope.outputs.x }}"}}},
+			Provenance: provenance,
+		}
+	}
+	catalog := []types.CompositeAction{
+		broken("linted", types.Provenance{Kind: types.SourceLocal, Root: "/src/app"}),
+		broken("other", types.Provenance{Kind: types.SourceLocal, Root: "/src/other"}),
+		broken("remote", types.Provenance{Kind: types.SourceRemote, Repo: "my-org/actions", Path: "remote/action.yml"}),
+	}
+
+	var files []string
+	for _, d := range CheckExpressions(catalog, nil, "/src/app") {
+		files = append(files, d.File)
+	}
+	if len(files) != 1 || files[0] != "linted/action.yml" {
+		t.Errorf("expected only the linted root to be checked, got %v", files)
+	}
+}
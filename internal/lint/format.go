@@ -0,0 +1,70 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatGithub = "github"
+)
+
+// Write prints diagnostics as human readable text, a JSON array or GitHub
+// Actions workflow commands that show up as annotations on a PR
+func Write(w io.Writer, diags []types.Diagnostic, format string) error {
+	switch format {
+	case FormatText, "":
+		for _, d := range diags {
+			if _, err := fmt.Fprintln(w, d.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatJSON:
+		if diags == nil {
+			diags = []types.Diagnostic{}
+		}
+		data, err := json.MarshalIndent(diags, "", "	")
+		if err != nil {
+			return fmt.Errorf("failed to marshal diagnostics: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case FormatGithub:
+		for _, d := range diags {
+			if _, err := fmt.Fprintln(w, githubAnnotation(d)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q, expected text, json or github", format)
+}
+
+// githubAnnotation renders a diagnostic as a ::error/::warning/::notice
+// workflow command
+func githubAnnotation(d types.Diagnostic) string {
+	props := []string{"file=" + escapeProperty(d.File)}
+	if d.Line > 0 {
+		props = append(props, fmt.Sprintf("line=%d", d.Line))
+	}
+	if d.Column > 0 {
+		props = append(props, fmt.Sprintf("col=%d", d.Column))
+	}
+	props = append(props, "title="+escapeProperty(string(d.Code)))
+	return fmt.Sprintf("::%s %s::%s", d.Severity, strings.Join(props, ","), escapeData(d.Message))
+}
+
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,73 @@
+package lint
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+func TestWrite(t *testing.T) {
+	diags := []types.Diagnostic{
+		{
+			File:     ".github/workflows/ci.yml",
+			Line:     12,
+			Column:   9,
+			Severity: types.SeverityError,
+			Code:     CodeUnknownInput,
+			Message:  "bad input\n100% wrong",
+		},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+		isError  bool
+	}{
+		{
+			format:   FormatText,
+			expected: ".github/workflows/ci.yml:12:9: error: bad input\n100% wrong (unknown-input)\n",
+		},
+		{
+			format:   FormatGithub,
+			expected: "::error file=.github/workflows/ci.yml,line=12,col=9,title=unknown-input::bad input%0A100%25 wrong\n",
+		},
+		{
+			format: FormatJSON,
+			expected: `[
+	{
+		"file": ".github/workflows/ci.yml",
+		"line": 12,
+		"column": 9,
+		"severity": "error",
+		"code": "unknown-input",
+		"message": "bad input\n100% wrong"
+	}
+]
+`,
+		},
+		{
+			format:  "xml",
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Write(&buf, diags, tt.format)
+			if tt.isError {
+				if err == nil {
+					t.Errorf("expected error for format %q", tt.format)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
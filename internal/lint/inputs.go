@@ -0,0 +1,149 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tnaucoin/stringer/internal/usage"
+	"github.com/tnaucoin/stringer/types"
+)
+
+const (
+	CodeMissingRequiredInput types.DiagnosticCode = "missing-required-input"
+	CodeUnknownInput         types.DiagnosticCode = "unknown-input"
+	CodeDeprecatedInput      types.DiagnosticCode = "deprecated-input"
+)
+
+// CheckCallers validates the `with:` inputs of every step in the workflows
+// and in the cataloged composite actions that calls a cataloged action
+func CheckCallers(catalog []types.CompositeAction, workflows []types.Workflow) []types.Diagnostic {
+	idx := usage.NewIndex(catalog)
+	var diags []types.Diagnostic
+
+	check := func(scope, repo, file string, step types.Step) {
+		if step.Uses == "" {
+			return
+		}
+		i, ok := idx.Resolve(step.Uses, scope)
+		if !ok {
+			return
+		}
+		if repo != "" {
+			file = repo + ":" + file
+		}
+		diags = append(diags, CheckStep(file, step, catalog[i])...)
+	}
+
+	for _, w := range workflows {
+		for _, job := range w.Jobs {
+			for _, step := range job.Steps {
+				check(usage.WorkflowScope(w), w.Repo, w.Path, step)
+			}
+		}
+	}
+	for _, a := range catalog {
+		repo := ""
+		if a.Provenance.Kind == types.SourceRemote {
+			repo = a.Provenance.Repo
+		}
+		for _, step := range a.Runs.Steps {
+			check(usage.ActionScope(a), repo, actionPath(a), step)
+		}
+	}
+
+	Sort(diags)
+	return diags
+}
+
+// CheckStep compares the inputs a step passes with the inputs the called
+// action declares
+func CheckStep(file string, step types.Step, action types.CompositeAction) []types.Diagnostic {
+	var diags []types.Diagnostic
+
+	passed := map[string]bool{}
+	for name := range step.With {
+		passed[strings.ToLower(name)] = true
+	}
+
+	for _, in := range action.Inputs {
+		// a required input with a default is always satisfied
+		if in.Required && in.Default == "" && !passed[strings.ToLower(in.Name)] {
+			diags = append(diags, types.Diagnostic{
+				File:     file,
+				Line:     step.Line,
+				Severity: types.SeverityError,
+				Code:     CodeMissingRequiredInput,
+				Message:  fmt.Sprintf("%s requires input %q", step.Uses, in.Name),
+			})
+		}
+	}
+
+	names := make([]string, 0, len(step.With))
+	for name := range step.With {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		line := step.WithLines[name]
+		if line == 0 {
+			line = step.Line
+		}
+		in, ok := action.Inputs.Get(name)
+		if !ok {
+			diags = append(diags, types.Diagnostic{
+				File:     file,
+				Line:     line,
+				Severity: types.SeverityError,
+				Code:     CodeUnknownInput,
+				Message:  fmt.Sprintf("%s has no input %q%s", step.Uses, name, suggestInput(action.Inputs)),
+			})
+			continue
+		}
+		if in.DeprecationMessage != "" {
+			diags = append(diags, types.Diagnostic{
+				File:     file,
+				Line:     line,
+				Severity: types.SeverityWarning,
+				Code:     CodeDeprecatedInput,
+				Message:  fmt.Sprintf("input %q of %s is deprecated: %s", name, step.Uses, in.DeprecationMessage),
+			})
+		}
+	}
+	return diags
+}
+
+func suggestInput(inputs types.ActionInputs) string {
+	if len(inputs) == 0 {
+		return ", it takes no inputs"
+	}
+	var names []string
+	for _, in := range inputs {
+		names = append(names, in.Name)
+	}
+	return ", expected one of " + strings.Join(names, ", ")
+}
+
+// Sort orders diagnostics by file and position
+func Sort(diags []types.Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].File != diags[j].File {
+			return diags[i].File < diags[j].File
+		}
+		if diags[i].Line != diags[j].Line {
+			return diags[i].Line < diags[j].Line
+		}
+		return diags[i].Column < diags[j].Column
+	})
+}
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diags []types.Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == types.SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+var deployAction = types.CompositeAction{
+	Name: "Deploy",
+	Inputs: types.ActionInputs{
+		{Name: "service", Required: true},
+		{Name: "region", Required: true, Default: "us-east-1"},
+		{Name: "zone", DeprecationMessage: "use region"},
+		{Name: "dry-run"},
+	},
+	Provenance: types.Provenance{
+		Kind: types.SourceRemote,
+		Repo: "my-org/actions",
+		Path: "deploy/action.yml",
+	},
+}
+
+func TestCheckStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		step     types.Step
+		expected []types.DiagnosticCode
+	}{
+		{
+			name:     "valid call",
+			step:     types.Step{Uses: "my-org/actions/deploy@v1", With: map[string]string{"service": "api", "DRY-RUN": "true"}},
+			expected: nil,
+		},
+		{
+			name:     "missing required input",
+			step:     types.Step{Uses: "my-org/actions/deploy@v1"},
+			expected: []types.DiagnosticCode{CodeMissingRequiredInput},
+		},
+		{
+			name:     "unknown and deprecated inputs",
+			step:     types.Step{Uses: "my-org/actions/deploy@v1", With: map[string]string{"service": "api", "zone": "a", "servce": "api"}},
+			expected: []types.DiagnosticCode{CodeUnknownInput, CodeDeprecatedInput},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var codes []types.DiagnosticCode
+			for _, d := range CheckStep("ci.yml", tt.step, deployAction) {
+				codes = append(codes, d.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, codes)
+			}
+		})
+	}
+}
+
+func TestCheckCallers(t *testing.T) {
+	workflows := []types.Workflow{
+		{
+			Path: ".github/workflows/deploy.yml",
+			Jobs: []types.Job{
+				{
+					ID: "deploy",
+					Steps: []types.Step{
+						{Uses: "actions/checkout@v4", With: map[string]string{"anything": "goes"}, Line: 6},
+						{
+							Uses:      "my-org/actions/deploy@v1",
+							With:      map[string]string{"zone": "b"},
+							Line:      7,
+							WithLines: map[string]int{"zone": 9},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	diags := CheckCallers([]types.CompositeAction{deployAction}, workflows)
+	expected := []types.Diagnostic{
+		{
+			File:     ".github/workflows/deploy.yml",
+			Line:     7,
+			Severity: types.SeverityError,
+			Code:     CodeMissingRequiredInput,
+			Message:  `my-org/actions/deploy@v1 requires input "service"`,
+		},
+		{
+			File:     ".github/workflows/deploy.yml",
+			Line:     9,
+			Severity: types.SeverityWarning,
+			Code:     CodeDeprecatedInput,
+			Message:  `input "zone" of my-org/actions/deploy@v1 is deprecated: use region`,
+		},
+	}
+	if !reflect.DeepEqual(diags, expected) {
+		t.Errorf("expected %+v, got %+v", expected, diags)
+	}
+	if !HasErrors(diags) {
+		t.Errorf("expected HasErrors to be true")
+	}
+}
+
+func TestCheckCallersLocalPaths(t *testing.T) {
+	// the tree was walked as lt/, both files are reported relative to it
+	build := types.CompositeAction{
+		Kind:       types.KindComposite,
+		Name:       "Build",
+		Inputs:     types.ActionInputs{{Name: "target", Required: true}},
+		Path:       "lt/build/action.yml",
+		Provenance: types.Provenance{Kind: types.SourceLocal, Path: "build/action.yml", Uses: "./build"},
+	}
+	release := types.CompositeAction{
+		Kind:       types.KindComposite,
+		Name:       "Release",
+		Runs:       types.Runs{Using: "composite", Steps: []types.Step{{Uses: "./build", Line: 8}}},
+		Path:       "lt/release/action.yml",
+		Provenance: types.Provenance{Kind: types.SourceLocal, Path: "release/action.yml", Uses: "./release"},
+	}
+	workflows := []types.Workflow{{
+		Path: "lt/.github/workflows/ci.yml",
+		Jobs: []types.Job{{ID: "ci", Steps: []types.Step{{Uses: "./build", Line: 9}}}},
+	}}
+
+	var files []string
+	for _, d := range CheckCallers([]types.CompositeAction{build, release}, workflows) {
+		files = append(files, d.File)
+	}
+	expected := []string{"lt/.github/workflows/ci.yml", "lt/release/action.yml"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected diagnostics in %v, got %v", expected, files)
+	}
+}
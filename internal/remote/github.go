@@ -1,24 +1,59 @@
 package remote
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
 
 	gp "github.com/tnaucoin/stringer/parser"
 	"github.com/tnaucoin/stringer/types"
 )
 
-const githubRawURL = "https://raw.githubusercontent.com"
+const (
+	// DefaultBaseURL is the github.com REST API, GitHub Enterprise Server
+	// instances serve the same API under https://<host>/api/v3
+	DefaultBaseURL   = "https://api.github.com"
+	DefaultUserAgent = "stringer"
+)
 
 type Fetcher struct {
-	Token string
+	Token     string
+	BaseURL   string
+	UserAgent string
+	Client    *http.Client
+
+	// MaxRetries bounds how often a timed out, 5xx or rate limited request
+	// is retried, MaxWait bounds a single rate limit sleep
+	MaxRetries int
+	MaxWait    time.Duration
+
+	// Cache, when set, holds the actions of repos already fetched at a
+	// commit, a ref still resolving to that commit is not fetched again
+	Cache Cache
+}
+
+// Cache stores the actions of a repo at a resolved commit SHA
+type Cache interface {
+	RepoActions(repo, sha string) ([]types.CompositeAction, bool)
+	SetRepoActions(repo, sha string, actions []types.CompositeAction)
 }
 
 func NewGithubFetcher(token string) *Fetcher {
 	return &Fetcher{
-		Token: token,
+		Token:     token,
+		BaseURL:   DefaultBaseURL,
+		UserAgent: DefaultUserAgent,
+		Client:    &http.Client{Timeout: DefaultTimeout},
+
+		MaxRetries: DefaultMaxRetries,
+		MaxWait:    DefaultMaxWait,
 	}
 }
 
@@ -27,48 +62,262 @@ type Options struct {
 	Ref  string
 }
 
-func (f *Fetcher) FetchCompositeActionsFromRepo(opts Options) ([]types.CompositeAction, error) {
+// treeEntry is a single item of a git tree as returned by the GitHub API
+type treeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	SHA  string `json:"sha"`
+}
+
+type treeResponse struct {
+	SHA       string      `json:"sha"`
+	Tree      []treeEntry `json:"tree"`
+	Truncated bool        `json:"truncated"`
+}
+
+func (f *Fetcher) FetchActionsFromRepo(opts Options) ([]types.CompositeAction, error) {
+	sha, err := f.resolve(&opts)
+	if err != nil {
+		return nil, err
+	}
+	if f.Cache != nil {
+		if cached, ok := f.Cache.RepoActions(opts.Repo, sha); ok {
+			return atRef(cached, opts.Ref), nil
+		}
+	}
+
+	var actions []types.CompositeAction
+	err = f.walkRepoYAML(opts.Repo, sha, isYAMLPath, func(path string, data []byte) {
+		action, err := gp.ParseActionFromBytes(data, path)
+		if err != nil {
+			// most YAML files in a repo are not actions
+			return
+		}
+		action.Provenance = types.Provenance{
+			Kind: types.SourceRemote,
+			Repo: opts.Repo,
+			Ref:  opts.Ref,
+			SHA:  sha,
+			Path: path,
+			Uses: types.UsesReference(opts.Repo, opts.Ref, path),
+		}
+		actions = append(actions, action)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if f.Cache != nil {
+		f.Cache.SetRepoActions(opts.Repo, sha, actions)
+	}
+	return actions, nil
+}
+
+// atRef copies cached actions, which may have been fetched through another
+// ref to the same commit, so their provenance names ref
+func atRef(cached []types.CompositeAction, ref string) []types.CompositeAction {
+	actions := make([]types.CompositeAction, len(cached))
+	for i, a := range cached {
+		a.Provenance.Ref = ref
+		a.Provenance.Uses = types.UsesReference(a.Provenance.Repo, ref, a.Provenance.Path)
+		actions[i] = a
+	}
+	return actions
+}
+
+// FetchWorkflowsFromRepo fetches and parses the workflows of a repository
+func (f *Fetcher) FetchWorkflowsFromRepo(opts Options) ([]types.Workflow, error) {
+	sha, err := f.resolve(&opts)
+	if err != nil {
+		return nil, err
+	}
+	var workflows []types.Workflow
+	err = f.walkRepoYAML(opts.Repo, sha, gp.IsWorkflowPath, func(path string, data []byte) {
+		workflow, err := gp.ParseWorkflowFromBytes(data, path)
+		if err != nil {
+			return
+		}
+		workflow.Repo = opts.Repo
+		workflow.Ref = opts.Ref
+		workflows = append(workflows, workflow)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return workflows, nil
+}
+
+// resolve defaults opts.Ref to main and returns the commit it points to
+func (f *Fetcher) resolve(opts *Options) (string, error) {
 	if opts.Repo == "" {
-		return nil, fmt.Errorf("repo is required")
+		return "", fmt.Errorf("repo is required")
 	}
 	if opts.Ref == "" {
 		opts.Ref = "main"
 	}
+	return f.ResolveRef(opts.Repo, opts.Ref)
+}
 
-	// TODO: placeholder for now replace with something real
-	paths := []string{
-		"",
+// walkRepoYAML calls fn with the contents of every YAML file in repo at
+// the commit sha whose path satisfies match. Files that fail to
+// download are skipped with a warning, rate limits abort the walk.
+func (f *Fetcher) walkRepoYAML(repo, sha string, match func(path string) bool, fn func(path string, data []byte)) error {
+	// tree and file contents are read at the resolved commit so the
+	// results are a consistent snapshot even if the ref moves meanwhile
+	paths, err := f.listYAMLFiles(repo, sha)
+	if err != nil {
+		return err
 	}
-	var actions []types.CompositeAction
 
 	for _, path := range paths {
-		data, err := f.fetchFileFromGithub(opts.Repo, opts.Ref, path)
-		if err != nil {
-			log.Printf("warning: fetch failed for %s: %v", path, err)
+		if !match(path) {
 			continue
 		}
-		action, err := gp.ParseCompositeActionFromBytes(data, path)
+		data, err := f.fetchFileFromGithub(repo, sha, path)
 		if err != nil {
-			log.Printf("warning: failed to parse %s: %v", path, err)
+			var rlErr *RateLimitError
+			if errors.As(err, &rlErr) {
+				return err
+			}
+			log.Printf("warning: fetch failed for %s: %v", path, err)
 			continue
 		}
-		actions = append(actions, action)
+		fn(path, data)
+	}
+	return nil
+}
 
+// ResolveRef returns the commit SHA a branch, tag or SHA points to
+func (f *Fetcher) ResolveRef(repo, ref string) (string, error) {
+	endpoint := fmt.Sprintf("/repos/%s/commits/%s", escapePath(repo), url.PathEscape(ref))
+	data, err := f.get(endpoint, "application/vnd.github.sha")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s@%s: %w", repo, ref, err)
 	}
-	return actions, nil
+	sha := strings.TrimSpace(string(data))
+	if sha == "" {
+		return "", fmt.Errorf("failed to resolve %s@%s: empty commit sha", repo, ref)
+	}
+	return sha, nil
+}
+
+// listYAMLFiles returns the path of every YAML blob in the repo tree at ref
+func (f *Fetcher) listYAMLFiles(repo, ref string) ([]string, error) {
+	tree, err := f.fetchTree(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	if tree.Truncated {
+		log.Printf("warning: tree for %s@%s was truncated by github, some files may be missing", repo, ref)
+	}
+
+	var paths []string
+	for _, entry := range tree.Tree {
+		if entry.Type == "blob" && isYAMLPath(entry.Path) {
+			paths = append(paths, entry.Path)
+		}
+	}
+	return paths, nil
+}
+
+func (f *Fetcher) fetchTree(repo, ref string) (*treeResponse, error) {
+	endpoint := fmt.Sprintf("/repos/%s/git/trees/%s?recursive=1", escapePath(repo), url.PathEscape(ref))
+	data, err := f.get(endpoint, "application/vnd.github+json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tree from github: %w", err)
+	}
+
+	var tree treeResponse
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to decode tree for %s@%s: %w", repo, ref, err)
+	}
+	return &tree, nil
 }
 
 func (f *Fetcher) fetchFileFromGithub(repo, ref, path string) ([]byte, error) {
-	url := fmt.Sprintf("%s/%s/%s/%s", githubRawURL, repo, ref, path)
-	resp, err := http.Get(url)
+	endpoint := fmt.Sprintf("/repos/%s/contents/%s?ref=%s", escapePath(repo), escapePath(path), url.QueryEscape(ref))
+	data, err := f.get(endpoint, "application/vnd.github.raw")
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch from github: %w", err)
 	}
+	return data, nil
+}
+
+// get performs an authenticated GET against the API and returns the body
+func (f *Fetcher) get(endpoint, accept string) ([]byte, error) {
+	data, _, err := f.getPage(endpoint, accept)
+	return data, err
+}
+
+// getPage is get for paginated endpoints, it also returns the URL of the
+// next page or an empty string on the last page
+func (f *Fetcher) getPage(endpoint, accept string) ([]byte, string, error) {
+	req, err := f.newRequest(http.MethodGet, endpoint, accept)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := f.do(req)
+	if err != nil {
+		return nil, "", err
+	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("github returned %d for %s", resp.StatusCode, url)
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("github returned %d for %s", resp.StatusCode, req.URL)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, nextPageURL(resp.Header.Get("Link")), nil
+}
+
+// newRequest builds an API request, endpoint is either a path relative to
+// BaseURL or an absolute URL such as a pagination link
+func (f *Fetcher) newRequest(method, endpoint, accept string) (*http.Request, error) {
+	target := endpoint
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		base := f.BaseURL
+		if base == "" {
+			base = DefaultBaseURL
+		}
+		target = strings.TrimRight(base, "/") + endpoint
+	}
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build github request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	} else {
+		req.Header.Set("User-Agent", DefaultUserAgent)
+	}
+	if f.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+f.Token)
+	}
+	return req, nil
+}
+
+func (f *Fetcher) client() *http.Client {
+	if f.Client != nil {
+		return f.Client
 	}
+	return defaultClient
+}
+
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// escapePath escapes each segment of a repository file path for use in a URL
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
 
-	return io.ReadAll(resp.Body)
+func isYAMLPath(p string) bool {
+	ext := strings.ToLower(path.Ext(p))
+	return ext == ".yml" || ext == ".yaml"
 }
@@ -0,0 +1,271 @@
+package remote
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+const testCompositeAction = `
+name: "Greet"
+description: "Says hello"
+runs:
+  using: "composite"
+  steps:
+    - run: echo "hello"
+      shell: bash
+`
+
+const testWorkflow = `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "hi"
+`
+
+func newTestGithub(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/my-repo/commits/main", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "deadbeef")
+	})
+	mux.HandleFunc("/repos/my-org/my-repo/git/trees/deadbeef", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("recursive") != "1" {
+			t.Errorf("expected recursive tree listing, got query %q", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"sha":"abc123","truncated":false,"tree":[
+			{"path":"README.md","type":"blob","sha":"1"},
+			{"path":".github","type":"tree","sha":"2"},
+			{"path":".github/workflows/ci.yml","type":"blob","sha":"3"},
+			{"path":"actions/greet/action.yml","type":"blob","sha":"4"},
+			{"path":"actions/broken/action.yaml","type":"blob","sha":"5"}
+		]}`)
+	})
+	mux.HandleFunc("/repos/my-org/my-repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != "deadbeef" {
+			t.Errorf("expected resolved commit ref, got query %q", r.URL.RawQuery)
+		}
+		if r.Header.Get("Accept") != "application/vnd.github.raw" {
+			t.Errorf("expected raw media type, got %q", r.Header.Get("Accept"))
+		}
+		content, ok := files[strings.TrimPrefix(r.URL.Path, "/repos/my-org/my-repo/contents/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, content)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchActionsFromRepo(t *testing.T) {
+	srv := newTestGithub(t, map[string]string{
+		".github/workflows/ci.yml": testWorkflow,
+		"actions/greet/action.yml": testCompositeAction,
+		// actions/broken/action.yaml is listed in the tree but missing
+	})
+
+	f := NewGithubFetcher("")
+	f.BaseURL = srv.URL
+	f.Client = srv.Client()
+
+	actions, err := f.FetchActionsFromRepo(Options{Repo: "my-org/my-repo", Ref: "main"})
+	if err != nil {
+		t.Fatalf("FetchActionsFromRepo returned error: %v", err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("expected 1 action, got %d", len(actions))
+	}
+	if actions[0].Name != "Greet" {
+		t.Errorf("expected action name %q, got %q", "Greet", actions[0].Name)
+	}
+	if actions[0].Path != "actions/greet/action.yml" {
+		t.Errorf("expected action path %q, got %q", "actions/greet/action.yml", actions[0].Path)
+	}
+
+	expected := types.Provenance{
+		Kind: types.SourceRemote,
+		Repo: "my-org/my-repo",
+		Ref:  "main",
+		SHA:  "deadbeef",
+		Path: "actions/greet/action.yml",
+		Uses: "my-org/my-repo/actions/greet@main",
+	}
+	if actions[0].Provenance != expected {
+		t.Errorf("expected provenance %+v, got %+v", expected, actions[0].Provenance)
+	}
+}
+
+// memoryCache is a Cache kept in a map
+type memoryCache map[string][]types.CompositeAction
+
+func (c memoryCache) RepoActions(repo, sha string) ([]types.CompositeAction, bool) {
+	actions, ok := c[repo+"@"+sha]
+	return actions, ok
+}
+
+func (c memoryCache) SetRepoActions(repo, sha string, actions []types.CompositeAction) {
+	c[repo+"@"+sha] = actions
+}
+
+func TestFetchActionsFromRepoCache(t *testing.T) {
+	srv := newTestGithub(t, map[string]string{
+		"actions/greet/action.yml": testCompositeAction,
+	})
+	cache := memoryCache{}
+	f := NewGithubFetcher("")
+	f.BaseURL = srv.URL
+	f.Client = srv.Client()
+	f.Cache = cache
+
+	if _, err := f.FetchActionsFromRepo(Options{Repo: "my-org/my-repo", Ref: "main"}); err != nil {
+		t.Fatalf("FetchActionsFromRepo returned error: %v", err)
+	}
+	if _, ok := cache["my-org/my-repo@deadbeef"]; !ok {
+		t.Fatalf("expected the actions to be cached by commit, got %v", cache)
+	}
+
+	// another ref to the same commit only resolves the ref
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/my-repo/commits/v1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "deadbeef")
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s for a cached commit", r.URL.Path)
+		http.NotFound(w, r)
+	})
+	resolveOnly := httptest.NewServer(mux)
+	defer resolveOnly.Close()
+	f.BaseURL = resolveOnly.URL
+
+	actions, err := f.FetchActionsFromRepo(Options{Repo: "my-org/my-repo", Ref: "v1"})
+	if err != nil {
+		t.Fatalf("FetchActionsFromRepo returned error: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Provenance.Uses != "my-org/my-repo/actions/greet@v1" {
+		t.Errorf("expected cached actions at the requested ref, got %+v", actions)
+	}
+	if cache["my-org/my-repo@deadbeef"][0].Provenance.Ref != "main" {
+		t.Errorf("expected the cached entry to be left untouched")
+	}
+}
+
+func TestFetchActionsFromRepoErrors(t *testing.T) {
+	srv := newTestGithub(t, nil)
+
+	f := NewGithubFetcher("")
+	f.BaseURL = srv.URL
+	f.Client = srv.Client()
+
+	if _, err := f.FetchActionsFromRepo(Options{}); err == nil {
+		t.Errorf("expected error when repo is empty")
+	}
+	if _, err := f.FetchActionsFromRepo(Options{Repo: "my-org/missing", Ref: "main"}); err == nil {
+		t.Errorf("expected error when tree listing fails")
+	}
+}
+
+func TestFetcherRequestHeaders(t *testing.T) {
+	var gotAuth, gotAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotAgent = r.Header.Get("User-Agent")
+		if strings.Contains(r.URL.Path, "/commits/") {
+			fmt.Fprint(w, "deadbeef")
+			return
+		}
+		fmt.Fprint(w, `{"sha":"abc123","tree":[]}`)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name          string
+		token         string
+		userAgent     string
+		expectedAuth  string
+		expectedAgent string
+	}{
+		{
+			name:          "token and default user agent",
+			token:         "secret-token",
+			expectedAuth:  "Bearer secret-token",
+			expectedAgent: DefaultUserAgent,
+		},
+		{
+			name:          "anonymous with custom user agent",
+			userAgent:     "my-portal/1.0",
+			expectedAuth:  "",
+			expectedAgent: "my-portal/1.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewGithubFetcher(tt.token)
+			f.BaseURL = srv.URL + "/api/v3/"
+			f.Client = srv.Client()
+			if tt.userAgent != "" {
+				f.UserAgent = tt.userAgent
+			}
+
+			if _, err := f.FetchActionsFromRepo(Options{Repo: "my-org/my-repo"}); err != nil {
+				t.Fatalf("FetchActionsFromRepo returned error: %v", err)
+			}
+			if gotAuth != tt.expectedAuth {
+				t.Errorf("expected Authorization %q, got %q", tt.expectedAuth, gotAuth)
+			}
+			if gotAgent != tt.expectedAgent {
+				t.Errorf("expected User-Agent %q, got %q", tt.expectedAgent, gotAgent)
+			}
+		})
+	}
+}
+
+func TestIsYAMLPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"action.yml", true},
+		{"nested/action.yaml", true},
+		{"UPPER.YML", true},
+		{"README.md", false},
+		{"yml", false},
+	}
+	for _, tt := range tests {
+		if got := isYAMLPath(tt.path); got != tt.expected {
+			t.Errorf("isYAMLPath(%q) = %v, expected %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestFetchWorkflowsFromRepo(t *testing.T) {
+	srv := newTestGithub(t, map[string]string{
+		".github/workflows/ci.yml": testWorkflow,
+		"actions/greet/action.yml": testCompositeAction,
+	})
+
+	f := NewGithubFetcher("")
+	f.BaseURL = srv.URL
+	f.Client = srv.Client()
+
+	workflows, err := f.FetchWorkflowsFromRepo(Options{Repo: "my-org/my-repo", Ref: "main"})
+	if err != nil {
+		t.Fatalf("FetchWorkflowsFromRepo returned error: %v", err)
+	}
+	if len(workflows) != 1 {
+		t.Fatalf("expected 1 workflow, got %d", len(workflows))
+	}
+	w := workflows[0]
+	if w.Path != ".github/workflows/ci.yml" || w.Repo != "my-org/my-repo" || w.Ref != "main" {
+		t.Errorf("unexpected workflow source %s %s@%s", w.Path, w.Repo, w.Ref)
+	}
+}
@@ -0,0 +1,197 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+const DefaultConcurrency = 4
+
+// Repository is the subset of the GitHub repository payload stringer uses
+type Repository struct {
+	Name          string   `json:"name"`
+	FullName      string   `json:"full_name"`
+	DefaultBranch string   `json:"default_branch"`
+	Visibility    string   `json:"visibility"`
+	Archived      bool     `json:"archived"`
+	Topics        []string `json:"topics"`
+}
+
+type OrgOptions struct {
+	Org string
+	// Ref overrides the ref scanned in every repo, empty uses each
+	// repository's default branch
+	Ref string
+
+	// Topic, Visibility and NameGlob filter the repositories scanned,
+	// empty values match everything
+	Topic      string
+	Visibility string
+	NameGlob   string
+
+	Concurrency int
+}
+
+func (o OrgOptions) matches(repo Repository) bool {
+	if o.Visibility != "" && !strings.EqualFold(o.Visibility, repo.Visibility) {
+		return false
+	}
+	if o.NameGlob != "" {
+		if ok, _ := path.Match(o.NameGlob, repo.Name); !ok {
+			return false
+		}
+	}
+	if o.Topic != "" {
+		found := false
+		for _, topic := range repo.Topics {
+			if strings.EqualFold(topic, o.Topic) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ListOrgRepos returns every repository in the organization, following
+// the API pagination until the last page
+func (f *Fetcher) ListOrgRepos(org string) ([]Repository, error) {
+	if org == "" {
+		return nil, fmt.Errorf("org is required")
+	}
+
+	var repos []Repository
+	endpoint := fmt.Sprintf("/orgs/%s/repos?per_page=100&type=all", url.PathEscape(org))
+	for endpoint != "" {
+		data, next, err := f.getPage(endpoint, "application/vnd.github+json")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repos for %s: %w", org, err)
+		}
+		var page []Repository
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to decode repos for %s: %w", org, err)
+		}
+		repos = append(repos, page...)
+		endpoint = next
+	}
+	return repos, nil
+}
+
+// FetchActionsFromOrg scans every matching repository in the
+// organization with a bounded pool of workers and returns one catalog
+func (f *Fetcher) FetchActionsFromOrg(opts OrgOptions) ([]types.CompositeAction, error) {
+	return fetchFromOrg(f, opts, f.FetchActionsFromRepo)
+}
+
+// FetchWorkflowsFromOrg is FetchActionsFromOrg for workflow files
+func (f *Fetcher) FetchWorkflowsFromOrg(opts OrgOptions) ([]types.Workflow, error) {
+	return fetchFromOrg(f, opts, f.FetchWorkflowsFromRepo)
+}
+
+// fetchFromOrg runs fetch for every matching repository in the org with a
+// bounded pool of workers, results keep the order of the repo listing
+func fetchFromOrg[T any](f *Fetcher, opts OrgOptions, fetch func(Options) ([]T, error)) ([]T, error) {
+	if opts.NameGlob != "" {
+		if _, err := path.Match(opts.NameGlob, ""); err != nil {
+			return nil, fmt.Errorf("invalid repo name pattern %q: %w", opts.NameGlob, err)
+		}
+	}
+	repos, err := f.ListOrgRepos(opts.Org)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []Repository
+	for _, repo := range repos {
+		if opts.matches(repo) {
+			matched = append(matched, repo)
+		}
+	}
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultConcurrency
+	}
+
+	results := make([][]T, len(matched))
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		fatalErr error
+	)
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mu.Lock()
+				stop := fatalErr != nil
+				mu.Unlock()
+				if stop {
+					continue
+				}
+
+				repo := matched[i]
+				repoOpts := Options{Repo: repo.FullName, Ref: opts.Ref}
+				if repoOpts.Ref == "" {
+					repoOpts.Ref = repo.DefaultBranch
+				}
+				items, err := fetch(repoOpts)
+				if err != nil {
+					var rlErr *RateLimitError
+					if errors.As(err, &rlErr) {
+						mu.Lock()
+						if fatalErr == nil {
+							fatalErr = err
+						}
+						mu.Unlock()
+						continue
+					}
+					log.Printf("warning: failed to scan %s@%s: %v", repoOpts.Repo, repoOpts.Ref, err)
+					continue
+				}
+				results[i] = items
+			}
+		}()
+	}
+
+	for i := range matched {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if fatalErr != nil {
+		return nil, fatalErr
+	}
+
+	var all []T
+	for _, items := range results {
+		all = append(all, items...)
+	}
+	return all, nil
+}
+
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="next"`)
+
+// nextPageURL extracts the rel="next" target from a Link header
+func nextPageURL(link string) string {
+	if m := linkNextPattern.FindStringSubmatch(link); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package remote
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestOrg(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	mux.HandleFunc("/orgs/my-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/orgs/my-org/repos?per_page=100&page=2>; rel="next", <%s/orgs/my-org/repos?per_page=100&page=2>; rel="last"`, srv.URL, srv.URL))
+			fmt.Fprint(w, `[
+				{"name":"actions-shared","full_name":"my-org/actions-shared","default_branch":"main","visibility":"internal","topics":["ci"]},
+				{"name":"service","full_name":"my-org/service","default_branch":"trunk","visibility":"private","topics":[]}
+			]`)
+		case "2":
+			fmt.Fprint(w, `[
+				{"name":"actions-public","full_name":"my-org/actions-public","default_branch":"develop","visibility":"public","topics":["ci","oss"]}
+			]`)
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	})
+	mux.HandleFunc("/repos/", func(w http.ResponseWriter, r *http.Request) {
+		// every repo holds a single action at its root
+		switch {
+		case strings.Contains(r.URL.Path, "/commits/"):
+			fmt.Fprint(w, "deadbeef")
+		case strings.Contains(r.URL.Path, "/git/trees/"):
+			fmt.Fprint(w, `{"sha":"abc123","tree":[{"path":"action.yml","type":"blob","sha":"1"}]}`)
+		case strings.Contains(r.URL.Path, "/contents/"):
+			fmt.Fprint(w, testCompositeAction)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestListOrgRepos(t *testing.T) {
+	srv := newTestOrg(t)
+	f := NewGithubFetcher("")
+	f.BaseURL = srv.URL
+	f.Client = srv.Client()
+
+	repos, err := f.ListOrgRepos("my-org")
+	if err != nil {
+		t.Fatalf("ListOrgRepos returned error: %v", err)
+	}
+	if len(repos) != 3 {
+		t.Fatalf("expected 3 repos across both pages, got %d", len(repos))
+	}
+	if repos[2].FullName != "my-org/actions-public" {
+		t.Errorf("expected last repo from page 2, got %q", repos[2].FullName)
+	}
+}
+
+func TestListOrgReposEscapes(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+	f := NewGithubFetcher("")
+	f.BaseURL = srv.URL
+	f.Client = srv.Client()
+
+	if _, err := f.ListOrgRepos("my org?type=x"); err != nil {
+		t.Fatalf("ListOrgRepos returned error: %v", err)
+	}
+	if path != "/orgs/my%20org%3Ftype=x/repos" {
+		t.Errorf("expected the org to be escaped, got %q", path)
+	}
+}
+
+func TestFetchActionsFromOrg(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     OrgOptions
+		expected []string // repo@ref of each action
+	}{
+		{
+			name:     "all repos at default branch",
+			opts:     OrgOptions{Org: "my-org"},
+			expected: []string{"my-org/actions-shared@main", "my-org/service@trunk", "my-org/actions-public@develop"},
+		},
+		{
+			name:     "explicit ref",
+			opts:     OrgOptions{Org: "my-org", Ref: "v1", Concurrency: 1},
+			expected: []string{"my-org/actions-shared@v1", "my-org/service@v1", "my-org/actions-public@v1"},
+		},
+		{
+			name:     "topic filter",
+			opts:     OrgOptions{Org: "my-org", Topic: "oss"},
+			expected: []string{"my-org/actions-public@develop"},
+		},
+		{
+			name:     "visibility filter",
+			opts:     OrgOptions{Org: "my-org", Visibility: "internal"},
+			expected: []string{"my-org/actions-shared@main"},
+		},
+		{
+			name:     "name glob filter",
+			opts:     OrgOptions{Org: "my-org", NameGlob: "actions-*"},
+			expected: []string{"my-org/actions-shared@main", "my-org/actions-public@develop"},
+		},
+	}
+
+	srv := newTestOrg(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewGithubFetcher("")
+			f.BaseURL = srv.URL
+			f.Client = srv.Client()
+
+			actions, err := f.FetchActionsFromOrg(tt.opts)
+			if err != nil {
+				t.Fatalf("FetchActionsFromOrg returned error: %v", err)
+			}
+			var got []string
+			for _, a := range actions {
+				got = append(got, a.Provenance.Repo+"@"+a.Provenance.Ref)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFetchActionsFromOrgInvalidGlob(t *testing.T) {
+	f := NewGithubFetcher("")
+	if _, err := f.FetchActionsFromOrg(OrgOptions{Org: "my-org", NameGlob: "["}); err == nil {
+		t.Errorf("expected error for invalid name glob")
+	}
+}
@@ -0,0 +1,142 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 3
+	// DefaultMaxWait is the longest the fetcher sleeps for a rate limit to
+	// reset before giving up with a RateLimitError
+	DefaultMaxWait = time.Minute
+	// DefaultTimeout bounds a single request, a stalled connection fails
+	// with a timeout that is then retried
+	DefaultTimeout = 30 * time.Second
+
+	baseBackoff = 500 * time.Millisecond
+	maxBackoff  = 30 * time.Second
+)
+
+// Variables to allow mocking in tests
+var sleep = time.Sleep
+var now = time.Now
+
+// RateLimitError is returned when github rejects a request because the API
+// quota is exhausted and the reset is too far away (or retries ran out)
+type RateLimitError struct {
+	URL        string
+	StatusCode int
+	Remaining  int
+	Reset      time.Time
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if !e.Reset.IsZero() {
+		return fmt.Sprintf("github rate limit exceeded for %s (status %d), resets at %s", e.URL, e.StatusCode, e.Reset.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("github rate limit exceeded for %s (status %d), retry after %s", e.URL, e.StatusCode, e.RetryAfter)
+}
+
+// wait returns how long to sleep before the request may be retried
+func (e *RateLimitError) wait(t time.Time) time.Duration {
+	if e.RetryAfter > 0 {
+		return e.RetryAfter
+	}
+	if !e.Reset.IsZero() {
+		// the reset header has second precision, pad to avoid waking early
+		if d := e.Reset.Sub(t) + time.Second; d > 0 {
+			return d
+		}
+	}
+	return baseBackoff
+}
+
+// do sends req, retrying timeouts and 5xx responses with jittered
+// exponential backoff and sleeping through short rate limit windows
+func (f *Fetcher) do(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := f.client().Do(req)
+		if err != nil {
+			if isTimeout(err) && attempt < f.MaxRetries {
+				sleep(backoff(attempt))
+				continue
+			}
+			return nil, err
+		}
+
+		if rl := rateLimitFromResponse(resp); rl != nil {
+			resp.Body.Close()
+			wait := rl.wait(now())
+			if attempt >= f.MaxRetries || wait > f.MaxWait {
+				return nil, rl
+			}
+			sleep(wait)
+			continue
+		}
+
+		if isTransientStatus(resp.StatusCode) && attempt < f.MaxRetries {
+			resp.Body.Close()
+			sleep(backoff(attempt))
+			continue
+		}
+		return resp, nil
+	}
+}
+
+// rateLimitFromResponse returns a RateLimitError if resp is a primary or
+// secondary rate limit rejection, nil otherwise
+func rateLimitFromResponse(resp *http.Response) *RateLimitError {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	rl := &RateLimitError{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Remaining:  -1,
+	}
+	if v, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		rl.Remaining = v
+	}
+	if v, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(v, 0)
+	}
+	if v, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		rl.RetryAfter = time.Duration(v) * time.Second
+	}
+
+	// a plain 403 is a permissions problem, not a rate limit
+	if resp.StatusCode == http.StatusForbidden && rl.Remaining != 0 && rl.RetryAfter == 0 {
+		return nil
+	}
+	return rl
+}
+
+func isTransientStatus(code int) bool {
+	switch code {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// backoff returns a full jitter exponential delay for the given attempt
+func backoff(attempt int) time.Duration {
+	d := baseBackoff << attempt
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(d))) + time.Millisecond
+}
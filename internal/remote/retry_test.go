@@ -0,0 +1,199 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetcherRetries(t *testing.T) {
+	fixedNow := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name          string
+		responses     []func(w http.ResponseWriter)
+		expectError   bool
+		expectRLError bool
+		expectedSleep []time.Duration // zero entries only check that a sleep happened
+	}{
+		{
+			name: "success without retries",
+			responses: []func(w http.ResponseWriter){
+				okTree,
+			},
+		},
+		{
+			name: "retries transient 5xx",
+			responses: []func(w http.ResponseWriter){
+				status(http.StatusBadGateway),
+				status(http.StatusServiceUnavailable),
+				okTree,
+			},
+			expectedSleep: []time.Duration{0, 0},
+		},
+		{
+			name: "gives up after max retries",
+			responses: []func(w http.ResponseWriter){
+				status(http.StatusInternalServerError),
+				status(http.StatusInternalServerError),
+				status(http.StatusInternalServerError),
+				status(http.StatusInternalServerError),
+			},
+			expectError:   true,
+			expectedSleep: []time.Duration{0, 0, 0},
+		},
+		{
+			name: "honours retry-after on secondary rate limit",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "7")
+					w.WriteHeader(http.StatusForbidden)
+				},
+				okTree,
+			},
+			expectedSleep: []time.Duration{7 * time.Second},
+		},
+		{
+			name: "waits for a near rate limit reset",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(fixedNow.Add(10*time.Second).Unix(), 10))
+					w.WriteHeader(http.StatusForbidden)
+				},
+				okTree,
+			},
+			expectedSleep: []time.Duration{11 * time.Second},
+		},
+		{
+			name: "returns rate limit error for a distant reset",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(fixedNow.Add(time.Hour).Unix(), 10))
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+			},
+			expectError:   true,
+			expectRLError: true,
+		},
+		{
+			name: "plain 403 is not retried",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "4999")
+					w.WriteHeader(http.StatusForbidden)
+				},
+			},
+			expectError: true,
+		},
+	}
+
+	originalSleep, originalNow := sleep, now
+	defer func() { sleep, now = originalSleep, originalNow }()
+	now = func() time.Time { return fixedNow }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var slept []time.Duration
+			sleep = func(d time.Duration) { slept = append(slept, d) }
+
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls >= len(tt.responses) {
+					t.Errorf("unexpected request %d", calls+1)
+					w.WriteHeader(http.StatusTeapot)
+					return
+				}
+				tt.responses[calls](w)
+				calls++
+			}))
+			defer srv.Close()
+
+			f := NewGithubFetcher("")
+			f.BaseURL = srv.URL
+			f.Client = srv.Client()
+
+			_, err := f.get("/repos/my-org/my-repo/git/trees/main", "application/vnd.github+json")
+			if tt.expectError && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			var rlErr *RateLimitError
+			if errors.As(err, &rlErr) != tt.expectRLError {
+				t.Errorf("expected rate limit error %v, got %v", tt.expectRLError, err)
+			}
+
+			if calls != len(tt.responses) {
+				t.Errorf("expected %d requests, got %d", len(tt.responses), calls)
+			}
+			if len(slept) != len(tt.expectedSleep) {
+				t.Fatalf("expected %d sleeps, got %v", len(tt.expectedSleep), slept)
+			}
+			for i, d := range tt.expectedSleep {
+				if d != 0 && slept[i] != d {
+					t.Errorf("sleep %d: expected %s, got %s", i, d, slept[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFetcherRetriesTimeout(t *testing.T) {
+	defer func() { sleep = time.Sleep }()
+	sleep = func(time.Duration) {}
+
+	release := make(chan struct{})
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// a stalled connection
+			<-release
+			return
+		}
+		okTree(w)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	f := NewGithubFetcher("")
+	if f.Client.Timeout != DefaultTimeout {
+		t.Fatalf("expected the default client to time out after %s, got %s", DefaultTimeout, f.Client.Timeout)
+	}
+	f.BaseURL = srv.URL
+	f.Client.Timeout = 50 * time.Millisecond
+
+	if _, err := f.get("/repos/my-org/my-repo/git/trees/main", "application/vnd.github+json"); err != nil {
+		t.Fatalf("expected the timed out request to be retried, got %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 100; attempt++ {
+		d := backoff(attempt)
+		if d <= 0 || d > maxBackoff+time.Millisecond {
+			t.Errorf("backoff(%d) = %s out of range", attempt, d)
+		}
+	}
+}
+
+func okTree(w http.ResponseWriter) {
+	fmt.Fprint(w, `{"sha":"abc123","tree":[]}`)
+}
+
+func status(code int) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(code)
+	}
+}
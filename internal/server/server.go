@@ -0,0 +1,240 @@
+// Package server exposes an action catalog over a small JSON HTTP API
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/tnaucoin/stringer/internal/docs"
+	"github.com/tnaucoin/stringer/internal/store"
+	"github.com/tnaucoin/stringer/types"
+)
+
+// RescanFunc scans the actions again and returns the new catalog
+type RescanFunc func() (*store.CacheFile, error)
+
+// Server serves a catalog and swaps it out when a rescan is triggered
+type Server struct {
+	mu      sync.RWMutex
+	catalog *store.CacheFile
+	ids     []string
+
+	rescan   RescanFunc
+	scanning sync.Mutex
+}
+
+// ActionSummary is an entry of the action list
+type ActionSummary struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Kind        types.ActionKind `json:"kind"`
+	Uses        string           `json:"uses,omitempty"`
+	Repo        string           `json:"repo,omitempty"`
+	Path        string           `json:"path"`
+}
+
+// Usage is the snippet showing how to call an action
+type Usage struct {
+	ID      string `json:"id"`
+	Uses    string `json:"uses"`
+	Snippet string `json:"snippet"`
+}
+
+// New returns a server for catalog, rescan may be nil to disable rescans
+func New(catalog *store.CacheFile, rescan RescanFunc) *Server {
+	s := &Server{rescan: rescan}
+	s.setCatalog(catalog)
+	return s
+}
+
+// Handler routes the API:
+//
+//	GET  /api/actions?q=&kind=    list and search actions
+//	GET  /api/actions/{id}        a single action
+//	GET  /api/actions/{id}/usage  a usage snippet for the action
+//	POST /api/rescan              scan again and replace the catalog
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/actions", s.listActions)
+	mux.HandleFunc("GET /api/actions/{id}", s.getAction)
+	mux.HandleFunc("GET /api/actions/{id}/usage", s.getUsage)
+	mux.HandleFunc("POST /api/rescan", s.triggerRescan)
+	return mux
+}
+
+// setCatalog replaces the served catalog, catalogs without a cache hash,
+// such as --output files, are hashed by content so ETags still work
+func (s *Server) setCatalog(catalog *store.CacheFile) {
+	if catalog == nil {
+		catalog = &store.CacheFile{}
+	}
+	if catalog.Hash == "" {
+		data, _ := json.Marshal(catalog.Actions)
+		sum := sha256.Sum256(data)
+		catalog.Hash = hex.EncodeToString(sum[:])
+	}
+	ids := types.ActionIDs(catalog.Actions)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.catalog = catalog
+	s.ids = ids
+}
+
+// snapshot returns the current catalog, which is never modified in place
+func (s *Server) snapshot() (*store.CacheFile, []string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.catalog, s.ids
+}
+
+func (s *Server) listActions(w http.ResponseWriter, r *http.Request) {
+	catalog, ids := s.snapshot()
+	if notModified(w, r, catalog.Hash) {
+		return
+	}
+
+	terms := strings.Fields(strings.ToLower(r.URL.Query().Get("q")))
+	kind := r.URL.Query().Get("kind")
+	summaries := []ActionSummary{}
+	for i, a := range catalog.Actions {
+		if kind != "" && string(a.Kind) != kind {
+			continue
+		}
+		if !matches(a, terms) {
+			continue
+		}
+		summaries = append(summaries, ActionSummary{
+			ID:          ids[i],
+			Name:        a.Name,
+			Description: a.Description,
+			Kind:        a.Kind,
+			Uses:        a.Provenance.Uses,
+			Repo:        a.Provenance.Repo,
+			Path:        a.Provenance.Path,
+		})
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+func (s *Server) getAction(w http.ResponseWriter, r *http.Request) {
+	catalog, ids := s.snapshot()
+	i, ok := find(ids, r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "no action with id "+r.PathValue("id"))
+		return
+	}
+	if notModified(w, r, catalog.Hash) {
+		return
+	}
+	writeJSON(w, http.StatusOK, catalog.Actions[i])
+}
+
+func (s *Server) getUsage(w http.ResponseWriter, r *http.Request) {
+	catalog, ids := s.snapshot()
+	i, ok := find(ids, r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "no action with id "+r.PathValue("id"))
+		return
+	}
+	if notModified(w, r, catalog.Hash) {
+		return
+	}
+	a := catalog.Actions[i]
+	writeJSON(w, http.StatusOK, Usage{
+		ID:      ids[i],
+		Uses:    a.Provenance.Uses,
+		Snippet: docs.UsageSnippet(a, ""),
+	})
+}
+
+// triggerRescan runs one rescan at a time, concurrent requests get 409
+func (s *Server) triggerRescan(w http.ResponseWriter, r *http.Request) {
+	if s.rescan == nil {
+		writeError(w, http.StatusNotImplemented, "rescans are disabled")
+		return
+	}
+	if !s.scanning.TryLock() {
+		writeError(w, http.StatusConflict, "a rescan is already running")
+		return
+	}
+	defer s.scanning.Unlock()
+
+	catalog, err := s.rescan()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "rescan failed: "+err.Error())
+		return
+	}
+	s.setCatalog(catalog)
+
+	catalog, _ = s.snapshot()
+	w.Header().Set("ETag", etag(catalog.Hash))
+	writeJSON(w, http.StatusOK, map[string]any{
+		"hash":    catalog.Hash,
+		"actions": len(catalog.Actions),
+	})
+}
+
+// matches reports whether every search term appears in the action's
+// name, description, uses reference or input names
+func matches(a types.CompositeAction, terms []string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	fields := []string{a.Name, a.Description, a.Provenance.Uses, a.Provenance.Repo}
+	for _, in := range a.Inputs {
+		fields = append(fields, in.Name)
+	}
+	text := strings.ToLower(strings.Join(fields, " "))
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func find(ids []string, id string) (int, bool) {
+	for i, candidate := range ids {
+		if candidate == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func etag(hash string) string {
+	return `"` + hash + `"`
+}
+
+// notModified sets the ETag of the response and answers 304 when the
+// client already has the current catalog
+func notModified(w http.ResponseWriter, r *http.Request, hash string) bool {
+	tag := etag(hash)
+	w.Header().Set("ETag", tag)
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == tag || candidate == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "	")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tnaucoin/stringer/internal/store"
+	"github.com/tnaucoin/stringer/types"
+)
+
+func testCatalog() *store.CacheFile {
+	return &store.CacheFile{
+		Hash: "abc",
+		Actions: []types.CompositeAction{
+			{
+				Kind:        types.KindComposite,
+				Name:        "Deploy",
+				Description: "Deploys a service",
+				Inputs:      types.ActionInputs{{Name: "service", Required: true}},
+				Provenance:  types.Provenance{Kind: types.SourceLocal, Path: "deploy/action.yml", Uses: "./deploy"},
+			},
+			{
+				Kind:       types.KindJavaScript,
+				Name:       "Notify",
+				Provenance: types.Provenance{Kind: types.SourceLocal, Path: "notify/action.yml", Uses: "./notify"},
+			},
+		},
+	}
+}
+
+func do(t *testing.T, h http.Handler, method, target string, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestListActions(t *testing.T) {
+	h := New(testCatalog(), nil).Handler()
+	tests := []struct {
+		target   string
+		expected []string
+	}{
+		{"/api/actions", []string{"deploy", "notify"}},
+		{"/api/actions?q=SERVICE", []string{"deploy"}},
+		{"/api/actions?kind=javascript", []string{"notify"}},
+		{"/api/actions?q=nothing", []string{}},
+	}
+	for _, tt := range tests {
+		rec := do(t, h, http.MethodGet, tt.target, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", tt.target, rec.Code)
+		}
+		var got []ActionSummary
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%s: invalid json: %v", tt.target, err)
+		}
+		ids := []string{}
+		for _, s := range got {
+			ids = append(ids, s.ID)
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("%s: expected %v, got %v", tt.target, tt.expected, ids)
+		}
+	}
+}
+
+func TestGetActionAndUsage(t *testing.T) {
+	h := New(testCatalog(), nil).Handler()
+
+	rec := do(t, h, http.MethodGet, "/api/actions/deploy", nil)
+	var action types.CompositeAction
+	if err := json.Unmarshal(rec.Body.Bytes(), &action); err != nil || action.Name != "Deploy" {
+		t.Errorf("unexpected action response %d %s", rec.Code, rec.Body)
+	}
+
+	rec = do(t, h, http.MethodGet, "/api/actions/deploy/usage", nil)
+	var usage Usage
+	if err := json.Unmarshal(rec.Body.Bytes(), &usage); err != nil {
+		t.Fatalf("invalid usage json: %v", err)
+	}
+	if usage.Uses != "./deploy" || !strings.Contains(usage.Snippet, "service: \"\"") {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+
+	if rec := do(t, h, http.MethodGet, "/api/actions/missing", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown action, got %d", rec.Code)
+	}
+}
+
+func TestETag(t *testing.T) {
+	h := New(testCatalog(), nil).Handler()
+
+	rec := do(t, h, http.MethodGet, "/api/actions", nil)
+	if tag := rec.Header().Get("ETag"); tag != `"abc"` {
+		t.Fatalf("expected the cache hash as ETag, got %q", tag)
+	}
+	for _, match := range []string{`"abc"`, `W/"abc"`, `"old", "abc"`} {
+		if rec := do(t, h, http.MethodGet, "/api/actions/deploy", map[string]string{"If-None-Match": match}); rec.Code != http.StatusNotModified {
+			t.Errorf("If-None-Match %s: expected 304, got %d", match, rec.Code)
+		}
+	}
+	if rec := do(t, h, http.MethodGet, "/api/actions", map[string]string{"If-None-Match": `"old"`}); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 for a stale ETag, got %d", rec.Code)
+	}
+
+	// catalogs without a hash are hashed by content
+	unhashed := testCatalog()
+	unhashed.Hash = ""
+	rec = do(t, New(unhashed, nil).Handler(), http.MethodGet, "/api/actions", nil)
+	if tag := rec.Header().Get("ETag"); len(tag) != 66 {
+		t.Errorf("expected a content hash ETag, got %q", tag)
+	}
+}
+
+func TestRescan(t *testing.T) {
+	updated := testCatalog()
+	updated.Hash = "def"
+	updated.Actions = updated.Actions[:1]
+	fail := false
+	s := New(testCatalog(), func() (*store.CacheFile, error) {
+		if fail {
+			return nil, errors.New("boom")
+		}
+		return updated, nil
+	})
+	h := s.Handler()
+
+	rec := do(t, h, http.MethodPost, "/api/rescan", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"def"` {
+		t.Fatalf("unexpected rescan response %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(t, h, http.MethodGet, "/api/actions/notify", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected the rescanned catalog to be served, got %d", rec.Code)
+	}
+
+	fail = true
+	if rec := do(t, h, http.MethodPost, "/api/rescan", nil); rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 for a failed rescan, got %d", rec.Code)
+	}
+	if rec := do(t, h, http.MethodGet, "/api/actions/deploy", nil); rec.Code != http.StatusOK {
+		t.Errorf("expected the previous catalog to be kept after a failed rescan, got %d", rec.Code)
+	}
+
+	if rec := do(t, New(testCatalog(), nil).Handler(), http.MethodPost, "/api/rescan", nil); rec.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 without a rescan func, got %d", rec.Code)
+	}
+	if rec := do(t, h, http.MethodGet, "/api/rescan", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET /api/rescan, got %d", rec.Code)
+	}
+}
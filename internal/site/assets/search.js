@@ -0,0 +1,32 @@
+// Filters the action list in place, every search term has to match the
+// indexed text of an action for it to stay visible
+(function () {
+  var search = document.getElementById("search");
+  var empty = document.getElementById("empty");
+  var items = document.querySelectorAll("#actions li");
+  var text = {};
+  (window.STRINGER_INDEX || []).forEach(function (entry) {
+    text[entry.id] = [entry.name, entry.description, entry.uses, entry.kind, entry.repo]
+      .concat(entry.inputs, entry.outputs)
+      .join(" ")
+      .toLowerCase();
+  });
+
+  function filter() {
+    var terms = search.value.toLowerCase().split(/\s+/).filter(Boolean);
+    var shown = 0;
+    items.forEach(function (item) {
+      var haystack = text[item.dataset.id] || "";
+      var match = terms.every(function (term) { return haystack.indexOf(term) >= 0; });
+      item.hidden = !match;
+      if (match) shown++;
+    });
+    empty.hidden = shown > 0;
+  }
+
+  search.addEventListener("input", filter);
+  if (window.location.hash.length > 1) {
+    search.value = decodeURIComponent(window.location.hash.slice(1));
+    filter();
+  }
+})();
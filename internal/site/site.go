@@ -0,0 +1,227 @@
+// Package site renders an action catalog as a static HTML site with a
+// page per action and a client side search
+package site
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tnaucoin/stringer/internal/docs"
+	"github.com/tnaucoin/stringer/internal/usage"
+	"github.com/tnaucoin/stringer/types"
+)
+
+const (
+	DefaultTitle     = "Actions catalog"
+	DefaultGithubURL = "https://github.com"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+//go:embed assets/*
+var assetFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+type Options struct {
+	Title string
+	// GithubURL is the web URL provenance links point to, set it for
+	// GitHub Enterprise Server
+	GithubURL string
+	// Generated is the build time shown in the footer, zero uses now
+	Generated time.Time
+}
+
+// page is everything a single action page shows
+type page struct {
+	ID        string
+	Action    types.CompositeAction
+	Uses      string
+	Snippet   string
+	SourceURL string
+	Calls     []call
+}
+
+// call is a `uses:` of a composite action step, ID is set when the called
+// action is part of the catalog
+type call struct {
+	Uses string
+	ID   string
+}
+
+// indexEntry is an action in the search index
+type indexEntry struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Kind        string   `json:"kind"`
+	Uses        string   `json:"uses"`
+	Repo        string   `json:"repo"`
+	Inputs      []string `json:"inputs"`
+	Outputs     []string `json:"outputs"`
+}
+
+// Build writes the site for actions into dir, creating it if needed
+func Build(dir string, actions []types.CompositeAction, opts Options) error {
+	if opts.Title == "" {
+		opts.Title = DefaultTitle
+	}
+	if opts.GithubURL == "" {
+		opts.GithubURL = DefaultGithubURL
+	}
+	if opts.Generated.IsZero() {
+		opts.Generated = time.Now()
+	}
+	// pages of actions that are gone must not outlive a rebuild
+	if err := os.RemoveAll(filepath.Join(dir, "actions")); err != nil {
+		return fmt.Errorf("failed to clear site directory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "actions"), 0755); err != nil {
+		return fmt.Errorf("failed to create site directory: %w", err)
+	}
+
+	pages := buildPages(actions, opts)
+	data := map[string]any{
+		"Title":     opts.Title,
+		"PageTitle": opts.Title,
+		"Root":      "",
+		"Generated": opts.Generated.Format("2006-01-02 15:04 MST"),
+		"Actions":   pages,
+	}
+	if err := render(filepath.Join(dir, "index.html"), "index.html", data); err != nil {
+		return err
+	}
+	data["Root"] = "../"
+	for _, p := range pages {
+		data["PageTitle"] = p.Action.Name + " · " + opts.Title
+		data["Page"] = p
+		if err := render(filepath.Join(dir, "actions", p.ID+".html"), "action.html", data); err != nil {
+			return err
+		}
+	}
+
+	if err := writeSearchIndex(filepath.Join(dir, "search-index.js"), pages); err != nil {
+		return err
+	}
+	return copyAssets(dir)
+}
+
+// buildPages collects the data of every action page
+func buildPages(actions []types.CompositeAction, opts Options) []page {
+	idx := usage.NewIndex(actions)
+	ids := types.ActionIDs(actions)
+
+	pages := make([]page, len(actions))
+	for i, a := range actions {
+		p := page{
+			ID:        ids[i],
+			Action:    a,
+			Uses:      a.Provenance.Uses,
+			Snippet:   docs.UsageSnippet(a, ""),
+			SourceURL: sourceURL(opts.GithubURL, a.Provenance),
+		}
+		for _, uses := range a.Calls() {
+			c := call{Uses: uses}
+			if j, ok := idx.Resolve(uses, usage.ActionScope(a)); ok {
+				c.ID = ids[j]
+			}
+			p.Calls = append(p.Calls, c)
+		}
+		pages[i] = p
+	}
+	return pages
+}
+
+// sourceURL links to the action file on GitHub, pinned to the scanned
+// commit when it is known. Local actions have no link.
+func sourceURL(githubURL string, p types.Provenance) string {
+	if p.Kind != types.SourceRemote || p.Repo == "" {
+		return ""
+	}
+	version := p.SHA
+	if version == "" {
+		version = p.Ref
+	}
+	return fmt.Sprintf("%s/%s/blob/%s/%s", strings.TrimRight(githubURL, "/"), p.Repo, version, p.Path)
+}
+
+func render(path, name string, data any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+	if err := templates.ExecuteTemplate(f, name, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeSearchIndex writes the index as a script rather than JSON so the
+// site also works when opened from disk, where fetch is not allowed
+func writeSearchIndex(path string, pages []page) error {
+	entries := make([]indexEntry, len(pages))
+	for i, p := range pages {
+		e := indexEntry{
+			ID:          p.ID,
+			Name:        p.Action.Name,
+			Description: p.Action.Description,
+			Kind:        string(p.Action.Kind),
+			Uses:        p.Uses,
+			Repo:        p.Action.Provenance.Repo,
+			Inputs:      []string{},
+			Outputs:     []string{},
+		}
+		for _, in := range p.Action.Inputs {
+			e.Inputs = append(e.Inputs, in.Name)
+		}
+		for _, out := range p.Action.Outputs {
+			e.Outputs = append(e.Outputs, out.Name)
+		}
+		entries[i] = e
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal search index: %w", err)
+	}
+	script := "window.STRINGER_INDEX = " + string(data) + ";\n"
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	return nil
+}
+
+func copyAssets(dir string) error {
+	entries, err := assetFS.ReadDir("assets")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		src, err := assetFS.Open("assets/" + entry.Name())
+		if err != nil {
+			return err
+		}
+		dst, err := os.Create(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			src.Close()
+			return fmt.Errorf("failed to create %s: %w", entry.Name(), err)
+		}
+		_, err = io.Copy(dst, src)
+		src.Close()
+		if cerr := dst.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+func TestBuild(t *testing.T) {
+	actions := []types.CompositeAction{
+		{
+			Kind:        types.KindComposite,
+			Name:        "Deploy",
+			Description: "Deploys <services>",
+			Inputs:      types.ActionInputs{{Name: "service", Required: true}},
+			Runs:        types.Runs{Using: "composite", Steps: []types.Step{{Uses: "./setup"}, {Uses: "actions/checkout@v4"}}},
+			Provenance: types.Provenance{
+				Kind: types.SourceRemote, Repo: "my-org/actions", Ref: "main", SHA: "abc123",
+				Path: "deploy/action.yml", Uses: "my-org/actions/deploy@main",
+			},
+		},
+		{
+			Kind: types.KindComposite,
+			Name: "Setup",
+			Runs: types.Runs{Using: "composite"},
+			Provenance: types.Provenance{
+				Kind: types.SourceRemote, Repo: "my-org/actions", Ref: "main",
+				Path: "setup/action.yml", Uses: "my-org/actions/setup@main",
+			},
+		},
+		// same ID as the first action
+		{
+			Kind:       types.KindJavaScript,
+			Name:       "Deploy too",
+			Runs:       types.Runs{Using: "node20", Main: "index.js"},
+			Provenance: types.Provenance{Kind: types.SourceRemote, Repo: "my-org/actions", Path: "deploy/action.yaml"},
+		},
+	}
+
+	dir := t.TempDir()
+	opts := Options{Title: "Shared actions", Generated: time.Date(2025, 1, 2, 3, 4, 0, 0, time.UTC)}
+	if err := Build(dir, actions, opts); err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected %s to be written: %v", name, err)
+		}
+		return string(data)
+	}
+
+	index := read("index.html")
+	for _, s := range []string{"<h1>Shared actions</h1>", `href="actions/my-org-actions-deploy.html"`, `href="actions/my-org-actions-deploy-2.html"`, "Deploys &lt;services&gt;"} {
+		if !strings.Contains(index, s) {
+			t.Errorf("expected index to contain %q", s)
+		}
+	}
+
+	deploy := read("actions/my-org-actions-deploy.html")
+	for _, s := range []string{
+		`href="https://github.com/my-org/actions/blob/abc123/deploy/action.yml"`,
+		`href="my-org-actions-setup.html"`,
+		"<code>actions/checkout@v4</code>",
+		"- uses: my-org/actions/deploy@main",
+		"2025-01-02 03:04 UTC",
+	} {
+		if !strings.Contains(deploy, s) {
+			t.Errorf("expected deploy page to contain %q", s)
+		}
+	}
+
+	search := read("search-index.js")
+	if !strings.HasPrefix(search, "window.STRINGER_INDEX = [") || !strings.Contains(search, `"inputs":["service"]`) {
+		t.Errorf("unexpected search index %s", search)
+	}
+	read("search.js")
+	read("style.css")
+
+	// a rebuild without the setup action drops its page
+	if err := Build(dir, actions[:1], opts); err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "actions", "my-org-actions-setup.html")); !os.IsNotExist(err) {
+		t.Errorf("expected the page of a removed action to be deleted, got %v", err)
+	}
+	read("actions/my-org-actions-deploy.html")
+}
+
+func TestSourceURL(t *testing.T) {
+	p := types.Provenance{Kind: types.SourceRemote, Repo: "my-org/actions", Ref: "v1", Path: "action.yml"}
+	if got := sourceURL("https://github.example.com/", p); got != "https://github.example.com/my-org/actions/blob/v1/action.yml" {
+		t.Errorf("unexpected source url %q", got)
+	}
+	if got := sourceURL(DefaultGithubURL, types.Provenance{Kind: types.SourceLocal, Path: "action.yml"}); got != "" {
+		t.Errorf("expected no link for local actions, got %q", got)
+	}
+}
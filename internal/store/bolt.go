@@ -0,0 +1,264 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tnaucoin/stringer/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+// openTimeout bounds how long opening waits for another process holding
+// the database
+const openTimeout = 30 * time.Second
+
+var (
+	revisionsBucket = []byte("revisions")
+	actionsBucket   = []byte("actions")
+	latestBucket    = []byte("latest")
+
+	// the indexes map lower cased value, NUL, source to nothing, they cover
+	// the latest revision of every source
+	nameIndex  = []byte("by_name")
+	inputIndex = []byte("by_input")
+	repoIndex  = []byte("by_repo")
+)
+
+// BoltStore is a Store in an embedded bbolt database. Every revision is
+// kept, revisions and their actions live in per source buckets keyed by
+// sequence, and queries go through indexes of the latest revisions so
+// they only decode the actions of matching sources.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// revisionJSON is the value of a revision, without its actions
+type revisionJSON struct {
+	Rev     string    `json:"rev"`
+	SavedAt time.Time `json:"saved_at"`
+	Count   int       `json:"count"`
+}
+
+// OpenBolt opens or creates the database at path
+func OpenBolt(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{revisionsBucket, actionsBucket, latestBucket, nameIndex, inputIndex, repoIndex} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Save(rev Revision) error {
+	if rev.SavedAt.IsZero() {
+		rev.SavedAt = now()
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		revs, err := tx.Bucket(revisionsBucket).CreateBucketIfNotExists([]byte(rev.Source))
+		if err != nil {
+			return err
+		}
+		actions, err := tx.Bucket(actionsBucket).CreateBucketIfNotExists([]byte(rev.Source))
+		if err != nil {
+			return err
+		}
+
+		latest := tx.Bucket(latestBucket)
+		if seq := latest.Get([]byte(rev.Source)); seq != nil {
+			var prev revisionJSON
+			if err := json.Unmarshal(revs.Get(seq), &prev); err != nil {
+				return err
+			}
+			if prev.Rev == rev.Rev {
+				return nil
+			}
+			var prevActions []types.CompositeAction
+			if err := json.Unmarshal(actions.Get(seq), &prevActions); err != nil {
+				return err
+			}
+			if err := updateIndexes(tx, rev.Source, prevActions, (*bolt.Bucket).Delete); err != nil {
+				return err
+			}
+		}
+
+		n, err := revs.NextSequence()
+		if err != nil {
+			return err
+		}
+		seq := make([]byte, 8)
+		binary.BigEndian.PutUint64(seq, n)
+		meta, err := json.Marshal(revisionJSON{Rev: rev.Rev, SavedAt: rev.SavedAt, Count: len(rev.Actions)})
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(rev.Actions)
+		if err != nil {
+			return err
+		}
+		if err := revs.Put(seq, meta); err != nil {
+			return err
+		}
+		if err := actions.Put(seq, data); err != nil {
+			return err
+		}
+		if err := latest.Put([]byte(rev.Source), seq); err != nil {
+			return err
+		}
+		return updateIndexes(tx, rev.Source, rev.Actions, func(b *bolt.Bucket, key []byte) error {
+			return b.Put(key, nil)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save %s@%s: %w", rev.Source, rev.Rev, err)
+	}
+	return nil
+}
+
+// updateIndexes calls fn with the index bucket and key of every value of
+// actions that is indexed
+func updateIndexes(tx *bolt.Tx, source string, actions []types.CompositeAction, fn func(b *bolt.Bucket, key []byte) error) error {
+	names, inputs, repos := tx.Bucket(nameIndex), tx.Bucket(inputIndex), tx.Bucket(repoIndex)
+	for _, a := range actions {
+		if err := fn(names, indexKey(a.Name, source)); err != nil {
+			return err
+		}
+		for _, in := range a.Inputs {
+			if err := fn(inputs, indexKey(in.Name, source)); err != nil {
+				return err
+			}
+		}
+		if a.Provenance.Repo != "" {
+			if err := fn(repos, indexKey(a.Provenance.Repo, source)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func indexKey(value, source string) []byte {
+	return []byte(strings.ToLower(value) + "\x00" + source)
+}
+
+func (s *BoltStore) Load() ([]types.CompositeAction, error) {
+	return s.Query(Query{})
+}
+
+func (s *BoltStore) Query(q Query) ([]types.CompositeAction, error) {
+	var matched []types.CompositeAction
+	err := s.db.View(func(tx *bolt.Tx) error {
+		sources, err := candidateSources(tx, q)
+		if err != nil {
+			return err
+		}
+		latest := tx.Bucket(latestBucket)
+		for _, source := range sources {
+			seq := latest.Get([]byte(source))
+			if seq == nil {
+				continue
+			}
+			var actions []types.CompositeAction
+			if err := json.Unmarshal(tx.Bucket(actionsBucket).Bucket([]byte(source)).Get(seq), &actions); err != nil {
+				return err
+			}
+			matched = append(matched, filterActions(actions, q)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query store: %w", err)
+	}
+	return matched, nil
+}
+
+// candidateSources narrows the sources worth loading for q down with the
+// indexes, a source matching every field may still hold no single action
+// that does
+func candidateSources(tx *bolt.Tx, q Query) ([]string, error) {
+	var sets []map[string]bool
+	if q.Repo != "" {
+		sets = append(sets, exactSources(tx.Bucket(repoIndex), q.Repo))
+	}
+	if q.Input != "" {
+		sets = append(sets, exactSources(tx.Bucket(inputIndex), q.Input))
+	}
+	if q.Name != "" {
+		name := strings.ToLower(q.Name)
+		found := map[string]bool{}
+		err := tx.Bucket(nameIndex).ForEach(func(k, _ []byte) error {
+			value, source, _ := bytes.Cut(k, []byte{0})
+			if strings.Contains(string(value), name) {
+				found[string(source)] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, found)
+	}
+
+	var sources []string
+	err := tx.Bucket(latestBucket).ForEach(func(k, _ []byte) error {
+		for _, set := range sets {
+			if !set[string(k)] {
+				return nil
+			}
+		}
+		sources = append(sources, string(k))
+		return nil
+	})
+	return sources, err
+}
+
+// exactSources returns the sources indexed under value
+func exactSources(index *bolt.Bucket, value string) map[string]bool {
+	found := map[string]bool{}
+	prefix := []byte(strings.ToLower(value) + "\x00")
+	c := index.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		found[string(k[len(prefix):])] = true
+	}
+	return found
+}
+
+func (s *BoltStore) History(source string) ([]Revision, error) {
+	var revs []Revision
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(revisionsBucket).Bucket([]byte(source))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(_, v []byte) error {
+			var r revisionJSON
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			revs = append(revs, Revision{Source: source, Rev: r.Rev, SavedAt: r.SavedAt, Count: r.Count})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %w", source, err)
+	}
+	return revs, nil
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,306 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+// CacheVersion is the schema version of the cache file. Files without a
+// version predate it and are migrated, newer ones are discarded. Version 2
+// records the lines of inputs, outputs and steps, entries written before
+// are scanned again.
+const CacheVersion = 2
+
+// ErrIncompatibleCache is returned for caches written by a newer stringer
+var ErrIncompatibleCache = errors.New("cache was written by a newer version of stringer")
+
+// Cache holds the results of scanning many targets in one file, keyed by
+// target: a local directory, or a repository at a resolved commit
+type Cache struct {
+	// Refresh makes scans and repo lookups ignore the cached entries, so
+	// each target scanned is parsed again and its entry replaced
+	Refresh bool
+
+	path    string
+	mu      sync.Mutex
+	entries map[string]*CacheFile
+	// changed records the targets set (true) or dropped (false) since the
+	// cache was opened, rewrite that the file needs rewriting regardless
+	changed map[string]bool
+	rewrite bool
+	// unrooted is a single target cache written before entries recorded
+	// their root, the next local scan adopts it
+	unrooted *CacheFile
+}
+
+// cacheJSON is the cache file layout
+type cacheJSON struct {
+	Version int                   `json:"version"`
+	Entries map[string]*CacheFile `json:"entries"`
+}
+
+// LocalTarget is the cache key of a local directory scan
+func LocalTarget(absRoot string) string {
+	return "local:" + absRoot
+}
+
+// RepoSource names a repository, the source of its remote scans
+func RepoSource(repo string) string {
+	return "github:" + repo
+}
+
+// RemoteTarget is the cache key of a repository scanned at a commit
+func RemoteTarget(repo, sha string) string {
+	return RepoSource(repo) + "@" + sha
+}
+
+func newCache(path string) *Cache {
+	return &Cache{path: path, entries: map[string]*CacheFile{}, changed: map[string]bool{}}
+}
+
+// OpenCache loads the cache at path, a missing file is an empty cache.
+// Truncated caches and caches of a newer schema are discarded with a
+// warning, they are rebuilt by the next scan.
+func OpenCache(path string) (*Cache, error) {
+	c := newCache(path)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+
+	if err := c.decode(data); err != nil {
+		if !discardable(err) {
+			return nil, fmt.Errorf("failed to unmarshal cache file: %w", err)
+		}
+		log.Printf("warning: discarding cache %s: %v", path, err)
+		c.entries = map[string]*CacheFile{}
+		c.rewrite = true
+	}
+	if c.unrooted != nil {
+		log.Printf("warning: cache %s does not record the directory it scanned, the next local scan adopts it and parses every file again", path)
+	}
+	return c, nil
+}
+
+// discardable reports whether a cache that failed to decode can be thrown
+// away, files of another format are left alone
+func discardable(err error) bool {
+	var syntaxErr *json.SyntaxError
+	return errors.Is(err, ErrIncompatibleCache) || errors.As(err, &syntaxErr)
+}
+
+// decode reads the entries of a cache file, migrating older versions
+func (c *Cache) decode(data []byte) error {
+	var file cacheJSON
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	if file.Version > CacheVersion {
+		return fmt.Errorf("%w (version %d, this one reads %d)", ErrIncompatibleCache, file.Version, CacheVersion)
+	}
+	if file.Entries != nil {
+		for target, entry := range file.Entries {
+			if entry != nil {
+				c.entries[target] = entry
+			}
+		}
+		c.rewrite = c.rewrite || file.Version < CacheVersion
+		return nil
+	}
+
+	// a single target cache without a root is kept until a local scan
+	// says which directory it belongs to
+	var legacy CacheFile
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	// a cache file written on its own predates versioned entries
+	legacy.Version = 0
+	if legacy.Root == "" {
+		c.unrooted = &legacy
+	} else {
+		c.entries[LocalTarget(legacy.Root)] = &legacy
+	}
+	c.rewrite = true
+	return nil
+}
+
+// adopt makes the unrooted cache the entry of a local target that has
+// none yet, reporting whether it did. The entry records no files, so the
+// scan adopting it parses every file again.
+func (c *Cache) adopt(target, absRoot string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unrooted == nil || c.entries[target] != nil {
+		return false
+	}
+	entry := c.unrooted
+	entry.Root = absRoot
+	c.entries[target] = entry
+	c.unrooted = nil
+	return true
+}
+
+// Save writes the cache if any entry changed since it was opened. Entries
+// other scans saved meanwhile are kept, the file is locked while it is
+// merged and replaced atomically.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.changed) == 0 && !c.rewrite {
+		return nil
+	}
+
+	unlock, err := lockFile(c.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current := newCache(c.path)
+	if data, err := os.ReadFile(c.path); err == nil {
+		// whatever can't be read is replaced, like on open
+		if err := current.decode(data); err != nil {
+			current.entries = map[string]*CacheFile{}
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read cache file: %w", err)
+	}
+	for target, set := range c.changed {
+		if set {
+			current.entries[target] = c.entries[target]
+		} else {
+			delete(current.entries, target)
+		}
+	}
+
+	if err := writeJSON(cacheJSON{Version: CacheVersion, Entries: current.entries}, c.path); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	c.entries = current.entries
+	c.changed = map[string]bool{}
+	c.rewrite = false
+	return nil
+}
+
+// Entry returns the cached scan of a target
+func (c *Cache) Entry(target string) (*CacheFile, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[target]
+	return entry, ok
+}
+
+// SetEntry stores the scan of a target
+func (c *Cache) SetEntry(target string, entry *CacheFile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.Version = CacheVersion
+	entry.SavedAt = now()
+	c.entries[target] = entry
+	c.changed[target] = true
+}
+
+// RepoActions returns the actions cached for repo at the commit sha
+func (c *Cache) RepoActions(repo, sha string) ([]types.CompositeAction, bool) {
+	entry, ok := c.Entry(RemoteTarget(repo, sha))
+	if !ok || c.Refresh || entry.Version < CacheVersion {
+		return nil, false
+	}
+	return entry.Actions, true
+}
+
+// SetRepoActions caches the actions of repo at sha, entries for other
+// commits of the repo are dropped since the ref moved on
+func (c *Cache) SetRepoActions(repo, sha string, actions []types.CompositeAction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setRevision(RepoSource(repo), sha, &CacheFile{Hash: sha, Repo: repo, SHA: sha, Actions: actions})
+}
+
+// setRevision replaces the entry of source. Local sources are targets
+// themselves, a repo keeps one target for the commit it was scanned at.
+func (c *Cache) setRevision(source, rev string, entry *CacheFile) {
+	target := source
+	if strings.HasPrefix(source, RepoSource("")) {
+		target = source + "@" + rev
+		for t := range c.entries {
+			if strings.HasPrefix(t, source+"@") {
+				delete(c.entries, t)
+				c.changed[t] = false
+			}
+		}
+	}
+	entry.Version = CacheVersion
+	entry.SavedAt = now()
+	c.entries[target] = entry
+	c.changed[target] = true
+}
+
+// revisions lists the entries saved for source
+func (c *Cache) revisions(source string) []Revision {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var revs []Revision
+	for _, target := range c.targets() {
+		if target != source && !strings.HasPrefix(target, source+"@") {
+			continue
+		}
+		entry := c.entries[target]
+		revs = append(revs, Revision{Source: source, Rev: entry.Hash, SavedAt: entry.SavedAt, Count: len(entry.Actions)})
+	}
+	return revs
+}
+
+// Targets lists the cached targets in order
+func (c *Cache) Targets() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.targets()
+}
+
+func (c *Cache) targets() []string {
+	targets := make([]string, 0, len(c.entries))
+	for target := range c.entries {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// Catalog merges every entry into one catalog, its hash changes whenever
+// any entry does
+func (c *Cache) Catalog() *CacheFile {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	targets := c.targets()
+
+	catalog := &CacheFile{Version: CacheVersion}
+	h := sha256.New()
+	if c.unrooted != nil {
+		io.WriteString(h, ":"+c.unrooted.Hash+"\n")
+		catalog.Actions = append(catalog.Actions, c.unrooted.Actions...)
+	}
+	for _, target := range targets {
+		entry := c.entries[target]
+		io.WriteString(h, target+":"+entry.Hash+"\n")
+		catalog.Actions = append(catalog.Actions, entry.Actions...)
+	}
+	catalog.Hash = hex.EncodeToString(h.Sum(nil))
+	return catalog
+}
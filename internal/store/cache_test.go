@@ -0,0 +1,299 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+func TestCacheTargets(t *testing.T) {
+	old := time.Now().Add(-time.Hour)
+	root := t.TempDir()
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	writeFile(t, filepath.Join(root, "local", "action.yml"), "name: local\ndescription: d\nruns:\n  using: composite\n  steps: []\n", old)
+
+	cache, err := OpenCache(cachePath)
+	if err != nil {
+		t.Fatalf("OpenCache failed on a missing file: %v", err)
+	}
+	if _, _, err := cache.ScanDirectory(root); err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	cache.SetRepoActions("my-org/actions", "sha1", []types.CompositeAction{{Name: "remote"}})
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	cache, err = OpenCache(cachePath)
+	if err != nil {
+		t.Fatalf("OpenCache failed: %v", err)
+	}
+	absRoot, _ := filepath.Abs(root)
+	expected := []string{RemoteTarget("my-org/actions", "sha1"), LocalTarget(absRoot)}
+	if got := cache.Targets(); len(got) != 2 || got[0] != expected[0] || got[1] != expected[1] {
+		t.Errorf("expected targets %v, got %v", expected, got)
+	}
+	// a remote scan leaves the local entry alone
+	if _, stats, _ := cache.ScanDirectory(root); stats.Reused != 1 || stats.Changed {
+		t.Errorf("expected the local entry to survive, got %+v", stats)
+	}
+
+	if actions, ok := cache.RepoActions("my-org/actions", "sha1"); !ok || actions[0].Name != "remote" {
+		t.Errorf("expected cached repo actions, got %+v", actions)
+	}
+	if _, ok := cache.RepoActions("my-org/actions", "sha2"); ok {
+		t.Errorf("expected another commit to miss")
+	}
+	// a new commit replaces the old one
+	cache.SetRepoActions("my-org/actions", "sha2", []types.CompositeAction{{Name: "remote v2"}})
+	if _, ok := cache.RepoActions("my-org/actions", "sha1"); ok {
+		t.Errorf("expected the previous commit to be dropped")
+	}
+
+	catalog := cache.Catalog()
+	if len(catalog.Actions) != 2 || catalog.Actions[0].Name != "remote v2" || catalog.Actions[1].Name != "local" {
+		t.Errorf("expected every target in the catalog, got %+v", catalog.Actions)
+	}
+	before := catalog.Hash
+	cache.SetRepoActions("my-org/actions", "sha3", nil)
+	if cache.Catalog().Hash == before {
+		t.Errorf("expected the catalog hash to change with an entry")
+	}
+
+	cache.Refresh = true
+	if _, ok := cache.RepoActions("my-org/actions", "sha3"); ok {
+		t.Errorf("expected a refresh to miss")
+	}
+}
+
+func TestOpenCacheLegacy(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+		// warn reports whether opening warns the cache has no root
+		warn bool
+	}{
+		{
+			name:     "local scan",
+			content:  `{"hash": "h", "root": "/src/actions", "actions": [{"name": "a"}]}`,
+			expected: []string{LocalTarget("/src/actions")},
+		},
+		{
+			// released caches never recorded the directory they scanned
+			name:    "scan without root",
+			content: `{"hash": "h", "actions": [{"name": "a"}]}`,
+			warn:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "cache.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write cache: %v", err)
+			}
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			defer log.SetOutput(os.Stderr)
+			cache, err := OpenCache(path)
+			if err != nil {
+				t.Fatalf("OpenCache failed: %v", err)
+			}
+			if warned := strings.Contains(logs.String(), "does not record the directory it scanned"); warned != tt.warn {
+				t.Errorf("expected warning %v, got %q", tt.warn, logs.String())
+			}
+			got := cache.Targets()
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected targets %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("expected targets %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "actions.json"), []byte(`[{"name": "a"}]`), 0644); err != nil {
+		t.Fatalf("failed to write actions: %v", err)
+	}
+	if _, err := OpenCache(filepath.Join(dir, "actions.json")); err == nil {
+		t.Errorf("expected an --output file not to open as a cache")
+	}
+}
+
+func TestCacheKeepsLines(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a", "action.yml"), "name: a\ndescription: d\ninputs:\n  target:\n    description: t\nruns:\n  using: composite\n  steps:\n    - uses: ./b\n      with:\n        x: y\n", time.Now())
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache, _ := OpenCache(path)
+	if _, _, err := cache.ScanDirectory(root); err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	cache, err := OpenCache(path)
+	if err != nil {
+		t.Fatalf("OpenCache failed: %v", err)
+	}
+	entry, stats, err := cache.ScanDirectory(root)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	a := entry.Actions[0]
+	if stats.Reused != 1 || a.Inputs[0].Line != 4 || a.Runs.Steps[0].Line != 9 || a.Runs.Steps[0].WithLines["x"] != 11 {
+		t.Errorf("expected cached positions to survive, got %+v %+v", stats, a)
+	}
+
+	// entries of version 1 were cached without positions
+	data, _ := os.ReadFile(path)
+	data = bytes.ReplaceAll(data, []byte(`"version": 2`), []byte(`"version": 1`))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write cache: %v", err)
+	}
+	cache, _ = OpenCache(path)
+	cache.SetRepoActions("my-org/actions", "sha", nil)
+	if _, stats, _ := cache.ScanDirectory(root); stats.Parsed != 1 || !stats.Changed {
+		t.Errorf("expected an older entry to be parsed again, got %+v", stats)
+	}
+	if _, ok := cache.RepoActions("my-org/actions", "sha"); !ok {
+		t.Errorf("expected a current entry to be reused")
+	}
+}
+
+func TestCacheAdoptsUnrooted(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "local", "action.yml"), "name: local\ndescription: d\nruns:\n  using: composite\n  steps: []\n", time.Now())
+	path := filepath.Join(t.TempDir(), "cache.json")
+	if err := os.WriteFile(path, []byte(`{"hash": "h", "actions": [{"name": "released"}]}`), 0644); err != nil {
+		t.Fatalf("failed to write cache: %v", err)
+	}
+
+	cache, err := OpenCache(path)
+	if err != nil {
+		t.Fatalf("OpenCache failed: %v", err)
+	}
+	if actions := cache.Catalog().Actions; len(actions) != 1 || actions[0].Name != "released" {
+		t.Errorf("expected the released actions until a scan adopts them, got %+v", actions)
+	}
+	entry, stats, err := cache.ScanDirectory(root)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if !stats.Changed || stats.Parsed != 1 || len(entry.Actions) != 1 || entry.Actions[0].Name != "local" {
+		t.Errorf("expected the adopted entry to be parsed again, got %+v %+v", stats, entry.Actions)
+	}
+	absRoot, _ := filepath.Abs(root)
+	if got := cache.Targets(); len(got) != 1 || got[0] != LocalTarget(absRoot) {
+		t.Errorf("expected the scanned root to own the cache, got %v", got)
+	}
+}
+
+func TestLoadCatalogTargets(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	cache, err := OpenCache(cachePath)
+	if err != nil {
+		t.Fatalf("OpenCache failed: %v", err)
+	}
+	cache.SetRepoActions("my-org/a", "sha", []types.CompositeAction{{Name: "a"}})
+	cache.SetRepoActions("my-org/b", "sha", []types.CompositeAction{{Name: "b"}})
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	actions, err := LoadCatalog(cachePath)
+	if err != nil {
+		t.Fatalf("LoadCatalog failed: %v", err)
+	}
+	if len(actions) != 2 || actions[0].Name != "a" || actions[1].Name != "b" {
+		t.Errorf("expected the actions of every target, got %+v", actions)
+	}
+}
+
+func TestCacheSaveMerges(t *testing.T) {
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, "cache.json")
+	seed, _ := OpenCache(cachePath)
+	seed.SetRepoActions("my-org/old", "sha", nil)
+	if err := seed.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// two scans opened the same cache before either saved
+	first, _ := OpenCache(cachePath)
+	second, _ := OpenCache(cachePath)
+	first.SetRepoActions("my-org/a", "sha", []types.CompositeAction{{Name: "a"}})
+	second.SetRepoActions("my-org/old", "new", nil)
+	if err := first.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := second.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	cache, err := OpenCache(cachePath)
+	if err != nil {
+		t.Fatalf("OpenCache failed: %v", err)
+	}
+	expected := []string{RemoteTarget("my-org/a", "sha"), RemoteTarget("my-org/old", "new")}
+	if got := cache.Targets(); len(got) != 2 || got[0] != expected[0] || got[1] != expected[1] {
+		t.Errorf("expected targets %v, got %v", expected, got)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to list dir: %v", err)
+	}
+	for _, e := range entries {
+		if e.Name() != "cache.json" {
+			t.Errorf("unexpected file %s left behind", e.Name())
+		}
+	}
+}
+
+func TestOpenCacheDiscards(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"truncated", `{"version": 1, "entries": {"local:/src": {"hash": "h", "act`},
+		{"newer version", `{"version": 99, "entries": {}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cache.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write cache: %v", err)
+			}
+			if actions, err := LoadCatalog(path); err != nil || len(actions) != 0 {
+				t.Errorf("expected LoadCatalog to discard the cache, got %+v, %v", actions, err)
+			}
+			cache, err := OpenCache(path)
+			if err != nil {
+				t.Fatalf("expected the cache to be discarded, got %v", err)
+			}
+			if len(cache.Targets()) != 0 {
+				t.Errorf("expected an empty cache, got %v", cache.Targets())
+			}
+			// saving replaces the file with the current version
+			if err := cache.Save(); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+			data, _ := os.ReadFile(path)
+			var file cacheJSON
+			if err := json.Unmarshal(data, &file); err != nil || file.Version != CacheVersion {
+				t.Errorf("expected a version %d cache, got %s", CacheVersion, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/tnaucoin/stringer/parser"
+)
+
+// Fingerprint identifies the content of a file. Size and ModTime let an
+// unchanged file reuse Hash without reading it again.
+type Fingerprint struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Hash    string    `json:"hash"`
+}
+
+// racyWindow covers coarse filesystem timestamps: a file modified this
+// close to being hashed may change again without its mtime moving, so
+// its fingerprint doesn't keep the mtime and the next run rehashes it
+const racyWindow = 2 * time.Second
+
+var now = time.Now
+
+// hashDirectory fingerprints the YAML files the parser would read under
+// rootDir, skipping the directories it skips, and combines them into one
+// hash. Files whose size and mtime match their known fingerprint are not
+// read again. Paths are relative so moving the checkout keeps the hash.
+var hashDirectory = func(rootDir string, known map[string]Fingerprint) (string, map[string]Fingerprint, error) {
+	started := now()
+	files := map[string]Fingerprint{}
+
+	err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != rootDir && parser.IsIgnoredDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !parser.IsYAMLFile(path) {
+			return nil
+		}
+		// Stat follows symlinks like the parser does when reading
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		fp, ok := known[rel]
+		if !ok || fp.ModTime.IsZero() || fp.Size != info.Size() || !fp.ModTime.Equal(info.ModTime()) {
+			digest, err := hashFile(path)
+			if err != nil {
+				return err
+			}
+			fp = Fingerprint{Size: info.Size(), ModTime: info.ModTime(), Hash: digest}
+		}
+		if started.Sub(fp.ModTime) < racyWindow {
+			fp.ModTime = time.Time{}
+		}
+		files[rel] = fp
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, p := range paths {
+		io.WriteString(h, p+":"+files[p].Hash+"\n")
+	}
+	return hex.EncodeToString(h.Sum(nil)), files, nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string, mtime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+}
+
+func TestHashDirectoryContent(t *testing.T) {
+	old := time.Now().Add(-time.Hour)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a", "action.yml"), "name: a\n", old)
+
+	hash1, files, err := hashDirectory(dir, nil)
+	if err != nil {
+		t.Fatalf("hashDirectory failed: %v", err)
+	}
+	if _, ok := files["a/action.yml"]; !ok || len(files) != 1 {
+		t.Fatalf("expected a fingerprint for a/action.yml, got %v", files)
+	}
+
+	// a fresh checkout rewrites every mtime without changing content
+	writeFile(t, filepath.Join(dir, "a", "action.yml"), "name: a\n", old.Add(time.Minute))
+	// files the parser doesn't read and ignored directories don't count
+	writeFile(t, filepath.Join(dir, "README.md"), "docs", old)
+	writeFile(t, filepath.Join(dir, ".git", "config.yml"), "x: 1", old)
+	writeFile(t, filepath.Join(dir, "node_modules", "pkg", "action.yml"), "name: dep\n", old)
+	writeFile(t, filepath.Join(dir, "web", "vendor", "action.yaml"), "name: dep\n", old)
+
+	hash2, _, err := hashDirectory(dir, files)
+	if err != nil {
+		t.Fatalf("hashDirectory failed: %v", err)
+	}
+	if hash1 != hash2 {
+		t.Errorf("expected the hash to only depend on YAML content")
+	}
+
+	// moving the tree keeps the hash
+	moved := filepath.Join(t.TempDir(), "moved")
+	if err := os.Rename(dir, moved); err != nil {
+		t.Fatalf("failed to move dir: %v", err)
+	}
+	if hash3, _, _ := hashDirectory(moved, nil); hash3 != hash1 {
+		t.Errorf("expected the hash to not depend on the root path")
+	}
+}
+
+func TestHashDirectoryFastPath(t *testing.T) {
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "action.yml")
+	writeFile(t, path, "name: a\n", old)
+
+	// a matching size and mtime reuses the known hash without reading
+	known := map[string]Fingerprint{"action.yml": {Size: 8, ModTime: old, Hash: "known"}}
+	_, files, err := hashDirectory(dir, known)
+	if err != nil {
+		t.Fatalf("hashDirectory failed: %v", err)
+	}
+	if files["action.yml"].Hash != "known" {
+		t.Errorf("expected the fast path to reuse the known hash, got %+v", files["action.yml"])
+	}
+
+	// a different size is rehashed
+	known["action.yml"] = Fingerprint{Size: 9, ModTime: old, Hash: "known"}
+	_, files, _ = hashDirectory(dir, known)
+	if files["action.yml"].Hash == "known" {
+		t.Errorf("expected a size change to rehash the file")
+	}
+}
+
+func TestHashDirectoryRacyMtime(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "action.yml")
+	mtime := time.Now().Truncate(time.Second)
+	writeFile(t, path, "name: a\n", mtime)
+
+	// hashed right after being written, the mtime can't be trusted
+	hash1, files, err := hashDirectory(dir, nil)
+	if err != nil {
+		t.Fatalf("hashDirectory failed: %v", err)
+	}
+	if !files["action.yml"].ModTime.IsZero() {
+		t.Fatalf("expected a racy fingerprint to drop its mtime, got %+v", files["action.yml"])
+	}
+
+	// an edit keeping size and mtime is still noticed
+	writeFile(t, path, "name: b\n", mtime)
+	hash2, _, err := hashDirectory(dir, files)
+	if err != nil {
+		t.Fatalf("hashDirectory failed: %v", err)
+	}
+	if hash1 == hash2 {
+		t.Errorf("expected a same-mtime edit to change the hash")
+	}
+}
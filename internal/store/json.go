@@ -1,110 +1,235 @@
 package store
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
-	"sort"
+	"time"
 
 	"github.com/tnaucoin/stringer/types"
 )
 
+// CacheFile is the scan of one target. Local scans set Root and Files,
+// remote ones Repo and SHA.
 type CacheFile struct {
+	// Version is the CacheVersion the entry was written with
+	Version int                     `json:"version,omitempty"`
 	Hash    string                  `json:"hash"`
 	Actions []types.CompositeAction `json:"actions"`
+	// Root is the absolute directory a local scan parsed
+	Root string `json:"root,omitempty"`
+	Repo string `json:"repo,omitempty"`
+	SHA  string `json:"sha,omitempty"`
+	// SavedAt is when the entry was last replaced
+	SavedAt time.Time `json:"saved_at,omitzero"`
+	// Files are the fingerprints Hash was computed from and what parsing
+	// each file produced, keyed by path relative to the scanned root
+	Files map[string]FileEntry `json:"files,omitempty"`
 }
 
+// FileEntry is the cached result of parsing one file
+type FileEntry struct {
+	Fingerprint
+	// Action is nil for YAML files that are not actions
+	Action      *types.CompositeAction `json:"action,omitempty"`
+	Diagnostics []types.Diagnostic     `json:"diagnostics,omitempty"`
+}
+
+// fingerprints returns the fingerprint of every cached file
+func (c *CacheFile) fingerprints() map[string]Fingerprint {
+	if c == nil {
+		return nil
+	}
+	fps := make(map[string]Fingerprint, len(c.Files))
+	for path, entry := range c.Files {
+		fps[path] = entry.Fingerprint
+	}
+	return fps
+}
+
+// SaveActionsWithHash caches actions as the scan of rootdir, alongside the
+// other targets cached in filepath
 func SaveActionsWithHash(actions []types.CompositeAction, rootdir, filepath string) error {
-	hash, err := hashDirectory(rootdir)
+	cache, err := OpenCache(filepath)
+	if err != nil {
+		return err
+	}
+	absRoot, err := absPath(rootdir)
+	if err != nil {
+		return err
+	}
+	target := LocalTarget(absRoot)
+	cache.adopt(target, absRoot)
+
+	// fingerprints of the previous entry spare rehashing unchanged files
+	previous, _ := cache.Entry(target)
+	hash, fps, err := hashDirectory(rootdir, previous.fingerprints())
 	if err != nil {
 		return fmt.Errorf("failed to hash directory: %w", err)
 	}
 
-	cache := CacheFile{
+	files := make(map[string]FileEntry, len(fps))
+	for path, fp := range fps {
+		files[path] = FileEntry{Fingerprint: fp}
+	}
+	cache.SetEntry(target, &CacheFile{
 		Hash:    hash,
 		Actions: actions,
-	}
+		Root:    absRoot,
+		Files:   files,
+	})
+	return cache.Save()
+}
 
-	data, err := json.MarshalIndent(cache, "", "	")
+// writeJSON replaces path with v as indented JSON. The data goes to a
+// temporary file that is renamed over path, so an interrupted write never
+// leaves a truncated file behind.
+func writeJSON(v any, path string) error {
+	data, err := json.MarshalIndent(v, "", "	")
 	if err != nil {
 		return fmt.Errorf("failed to marshal actions: %w", err)
 	}
-	if err := os.WriteFile(filepath, data, 0644); err != nil {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write JSON to file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write JSON to file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write JSON to file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write JSON to file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write JSON to file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
 		return fmt.Errorf("failed to write JSON to file: %w", err)
 	}
 	return nil
 }
 
+// IsCacheValid reports whether the entry cached for rootDir matches the
+// files under it
 func IsCacheValid(rootDir, cachePath string) (bool, error) {
-	cache, err := LoadCache(cachePath)
+	cache, err := readCache(cachePath)
 	if err != nil {
 		return false, err
 	}
-	currentHash, err := hashDirectory(rootDir)
+	absRoot, err := absPath(rootDir)
+	if err != nil {
+		return false, err
+	}
+	target := LocalTarget(absRoot)
+	cache.adopt(target, absRoot)
+	entry, ok := cache.Entry(target)
+	if !ok {
+		return false, nil
+	}
+	currentHash, _, err := hashDirectory(rootDir, entry.fingerprints())
 	if err != nil {
 		return false, err
 	}
-	return currentHash == cache.Hash, nil
+	return currentHash == entry.Hash, nil
 }
 
 func SaveActions(actions []types.CompositeAction, filepath string) error {
-	data, err := json.MarshalIndent(actions, "", "	")
-	if err != nil {
-		return fmt.Errorf("failed to marshal actions: %w", err)
-	}
-	if err := os.WriteFile(filepath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write JSON to file: %w", err)
-	}
-	return nil
+	return writeJSON(actions, filepath)
 }
 
 func LoadActions(filepath string) (*CacheFile, error) {
+	return LoadCache(filepath)
+}
+
+// LoadCatalog reads actions from either a cache file or a file written by
+// SaveActions, whichever format filepath holds. The actions of every
+// target in a cache are returned. Truncated caches and caches of a newer
+// schema are discarded with a warning like OpenCache does.
+func LoadCatalog(filepath string) ([]types.CompositeAction, error) {
 	data, err := os.ReadFile(filepath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read catalog file: %w", err)
 	}
-	var cache CacheFile
-	if err := json.Unmarshal(data, &cache); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	var actions []types.CompositeAction
+	if err := json.Unmarshal(data, &actions); err == nil {
+		return actions, nil
+	}
+	var file cacheJSON
+	if err := json.Unmarshal(data, &file); err != nil {
+		// only a file that starts out as a cache is a truncated one
+		if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+			return nil, fmt.Errorf("failed to unmarshal catalog file: %w", err)
+		}
+		return discardCatalog(filepath, err)
+	}
+	if file.Entries == nil && file.Version == 0 {
+		// caches written before they held many targets
+		var legacy CacheFile
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal catalog file: %w", err)
+		}
+		return legacy.Actions, nil
+	}
+	cache := newCache(filepath)
+	if err := cache.decode(data); err != nil {
+		return discardCatalog(filepath, err)
+	}
+	return cache.Catalog().Actions, nil
+}
+
+// discardCatalog returns an empty catalog for a cache OpenCache would
+// discard, other errors are returned as is
+func discardCatalog(path string, err error) ([]types.CompositeAction, error) {
+	if !discardable(err) {
+		return nil, fmt.Errorf("failed to unmarshal catalog file: %w", err)
 	}
-	return &cache, nil
+	log.Printf("warning: discarding cache %s: %v", path, err)
+	return nil, nil
 }
 
+// LoadCache reads the cache at filepath as one file: the entry of a cache
+// holding a single target, or the catalog of all of them
 func LoadCache(filepath string) (*CacheFile, error) {
-	data, err := os.ReadFile(filepath)
+	cache, err := readCache(filepath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read cache file: %w", err)
+		return nil, err
 	}
-	var cache CacheFile
-	if err := json.Unmarshal(data, &cache); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal cache file: %w", err)
+	if cache.unrooted != nil {
+		return cache.unrooted, nil
 	}
-	return &cache, nil
+	if targets := cache.Targets(); len(targets) == 1 {
+		entry, _ := cache.Entry(targets[0])
+		return entry, nil
+	}
+	return cache.Catalog(), nil
 }
 
-var hashDirectory = func(rootDir string) (string, error) {
-	var entries []string
-
-	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			entries = append(entries, fmt.Sprintf("%s:%d", path, info.ModTime().UnixNano()))
-		}
-		return nil
-	})
+// readCache decodes the cache at path, unlike OpenCache a missing or
+// unreadable file is an error
+func readCache(path string) (*Cache, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
 	}
+	cache := newCache(path)
+	if err := cache.decode(data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cache file: %w", err)
+	}
+	return cache, nil
+}
 
-	sort.Strings(entries)
-	h := sha256.New()
-	for _, entry := range entries {
-		h.Write([]byte(entry))
+func absPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
 	}
-	return hex.EncodeToString(h.Sum(nil)), nil
+	return abs, nil
 }
@@ -2,6 +2,7 @@ package store
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -19,9 +20,17 @@ func TestSaveActionsWithHash(t *testing.T) {
 		{
 			Name:        "Test Action",
 			Description: "A test action",
-			Inputs:      map[string]any{"input1": map[string]any{"description": "Test input"}},
-			Outputs:     map[string]any{"output1": map[string]any{"description": "Test output"}},
+			Inputs:      types.ActionInputs{{Name: "input1", Description: "Test input"}},
+			Outputs:     types.ActionOutputs{{Name: "output1", Description: "Test output"}},
 			Path:        "test/path",
+			Provenance: types.Provenance{
+				Kind: types.SourceRemote,
+				Repo: "my-org/actions",
+				Ref:  "v1",
+				SHA:  "deadbeef",
+				Path: "test/path/action.yml",
+				Uses: "my-org/actions/test/path@v1",
+			},
 		},
 	}
 
@@ -49,6 +58,10 @@ func TestSaveActionsWithHash(t *testing.T) {
 	if cache.Hash == "" {
 		t.Errorf("Hash was not generated")
 	}
+
+	if cache.Actions[0].Provenance != actions[0].Provenance {
+		t.Errorf("Expected provenance %+v, got %+v", actions[0].Provenance, cache.Actions[0].Provenance)
+	}
 }
 
 func TestIsCacheValid(t *testing.T) {
@@ -83,8 +96,8 @@ func TestIsCacheValid(t *testing.T) {
 	defer func() { hashDirectory = originalHashDir }()
 
 	// Replace with mock function
-	hashDirectory = func(rootDir string) (string, error) {
-		return constantHash, nil
+	hashDirectory = func(rootDir string, known map[string]Fingerprint) (string, map[string]Fingerprint, error) {
+		return constantHash, nil, nil
 	}
 
 	// Test cache validity - should be valid with our mock
@@ -97,8 +110,8 @@ func TestIsCacheValid(t *testing.T) {
 	}
 
 	// Now change the mock to return a different hash
-	hashDirectory = func(rootDir string) (string, error) {
-		return "differenthash", nil
+	hashDirectory = func(rootDir string, known map[string]Fingerprint) (string, map[string]Fingerprint, error) {
+		return "differenthash", nil, nil
 	}
 
 	// Test cache validity again - should be invalid now
@@ -221,6 +234,15 @@ func TestLoadCacheErrors(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected error when loading invalid JSON, got nil")
 	}
+
+	// Test loading a cache written by a newer version
+	if err := os.WriteFile(cachePath, []byte(`{"version": 99, "hash": "h"}`), 0644); err != nil {
+		t.Fatalf("Failed to write newer cache file: %v", err)
+	}
+	_, err = LoadCache(cachePath)
+	if !errors.Is(err, ErrIncompatibleCache) {
+		t.Errorf("Expected ErrIncompatibleCache, got %v", err)
+	}
 }
 
 func TestHashDirectory(t *testing.T) {
@@ -228,7 +250,7 @@ func TestHashDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// Get initial hash
-	hash1, err := hashDirectory(tmpDir)
+	hash1, _, err := hashDirectory(tmpDir, nil)
 	if err != nil {
 		t.Fatalf("hashDirectory failed: %v", err)
 	}
@@ -237,13 +259,13 @@ func TestHashDirectory(t *testing.T) {
 		t.Errorf("Expected non-empty hash")
 	}
 
-	// Create a file and check that hash changes
-	filePath := filepath.Join(tmpDir, "testfile.txt")
+	// Create a YAML file and check that hash changes
+	filePath := filepath.Join(tmpDir, "testfile.yml")
 	if err := os.WriteFile(filePath, []byte("test content"), 0644); err != nil {
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	hash2, err := hashDirectory(tmpDir)
+	hash2, _, err := hashDirectory(tmpDir, nil)
 	if err != nil {
 		t.Fatalf("hashDirectory failed after adding file: %v", err)
 	}
@@ -257,7 +279,7 @@ func TestHashDirectory(t *testing.T) {
 		t.Fatalf("Failed to modify test file: %v", err)
 	}
 
-	hash3, err := hashDirectory(tmpDir)
+	hash3, _, err := hashDirectory(tmpDir, nil)
 	if err != nil {
 		t.Fatalf("hashDirectory failed after modifying file: %v", err)
 	}
@@ -266,3 +288,85 @@ func TestHashDirectory(t *testing.T) {
 		t.Errorf("Hash should change after modifying a file")
 	}
 }
+
+func TestLoadCacheLegacyInputs(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+
+	// cache written before inputs and outputs were typed
+	legacy := `{
+	"hash": "legacyhash",
+	"actions": [
+		{
+			"name": "Legacy Action",
+			"description": "Stored with raw maps",
+			"inputs": {
+				"zone": {"description": "Zone", "required": true, "default": "a"},
+				"count": {"required": "false", "default": 2}
+			},
+			"outputs": {
+				"result": {"description": "Result", "value": "${{ steps.run.outputs.result }}"}
+			}
+		}
+	]
+}`
+	if err := os.WriteFile(cachePath, []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write legacy cache file: %v", err)
+	}
+
+	cache, err := LoadCache(cachePath)
+	if err != nil {
+		t.Fatalf("LoadCache failed on legacy cache: %v", err)
+	}
+
+	action := cache.Actions[0]
+	expectedInputs := types.ActionInputs{
+		{Name: "zone", Description: "Zone", Required: true, Default: "a"},
+		{Name: "count", Default: "2"},
+	}
+	if len(action.Inputs) != len(expectedInputs) {
+		t.Fatalf("Expected %d inputs, got %d", len(expectedInputs), len(action.Inputs))
+	}
+	for i, in := range expectedInputs {
+		if action.Inputs[i] != in {
+			t.Errorf("Expected input %+v, got %+v", in, action.Inputs[i])
+		}
+	}
+	if out, ok := action.Outputs.Get("result"); !ok || out.Value != "${{ steps.run.outputs.result }}" {
+		t.Errorf("Expected migrated output value, got %+v", action.Outputs)
+	}
+}
+
+func TestLoadCatalog(t *testing.T) {
+	tmpDir := t.TempDir()
+	actions := []types.CompositeAction{
+		{Name: "Action 1", Description: "First test action"},
+	}
+
+	outputPath := filepath.Join(tmpDir, "actions.json")
+	if err := SaveActions(actions, outputPath); err != nil {
+		t.Fatalf("SaveActions failed: %v", err)
+	}
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	if err := SaveActionsWithHash(actions, tmpDir, cachePath); err != nil {
+		t.Fatalf("SaveActionsWithHash failed: %v", err)
+	}
+
+	for _, path := range []string{outputPath, cachePath} {
+		loaded, err := LoadCatalog(path)
+		if err != nil {
+			t.Fatalf("LoadCatalog(%s) failed: %v", filepath.Base(path), err)
+		}
+		if len(loaded) != 1 || loaded[0].Name != "Action 1" {
+			t.Errorf("LoadCatalog(%s) returned unexpected actions %+v", filepath.Base(path), loaded)
+		}
+	}
+
+	invalidPath := filepath.Join(tmpDir, "invalid.json")
+	if err := os.WriteFile(invalidPath, []byte("invalid json"), 0644); err != nil {
+		t.Fatalf("Failed to write invalid file: %v", err)
+	}
+	if _, err := LoadCatalog(invalidPath); err == nil {
+		t.Errorf("Expected error when loading invalid JSON, got nil")
+	}
+}
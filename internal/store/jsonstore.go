@@ -0,0 +1,55 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+// JSONStore is a Store kept in a scan cache file. The file only holds the
+// latest revision of every source, so History has at most one entry.
+type JSONStore struct {
+	cache *Cache
+}
+
+// OpenJSON opens the cache file at path as a Store
+func OpenJSON(path string) (*JSONStore, error) {
+	cache, err := OpenCache(path)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONStore{cache: cache}, nil
+}
+
+func (s *JSONStore) Save(rev Revision) error {
+	// a scan that already cached this revision keeps its file entries
+	for _, r := range s.cache.revisions(rev.Source) {
+		if r.Rev == rev.Rev {
+			return nil
+		}
+	}
+	entry := &CacheFile{Hash: rev.Rev, Actions: rev.Actions}
+	if repo, ok := strings.CutPrefix(rev.Source, RepoSource("")); ok {
+		entry.Repo, entry.SHA = repo, rev.Rev
+	}
+	s.cache.mu.Lock()
+	s.cache.setRevision(rev.Source, rev.Rev, entry)
+	s.cache.mu.Unlock()
+	return s.cache.Save()
+}
+
+func (s *JSONStore) Load() ([]types.CompositeAction, error) {
+	return s.cache.Catalog().Actions, nil
+}
+
+func (s *JSONStore) Query(q Query) ([]types.CompositeAction, error) {
+	return filterActions(s.cache.Catalog().Actions, q), nil
+}
+
+func (s *JSONStore) History(source string) ([]Revision, error) {
+	return s.cache.revisions(source), nil
+}
+
+func (s *JSONStore) Close() error {
+	return nil
+}
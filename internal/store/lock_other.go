@@ -0,0 +1,40 @@
+//go:build !unix
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// lockTimeout bounds how long a scan waits for another to release the
+// cache before giving up
+const lockTimeout = 30 * time.Second
+
+// lockFile takes an exclusive lock guarding path by creating its lock
+// file, waiting for other scans writing the same file to remove
+// theirs
+func lockFile(path string) (unlock func() error, err error) {
+	lock, err := lockPath(path)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() error { return os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to lock cache file: %w", err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("cache file is locked by another scan, remove %s if none is running", lock)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tnaucoin/stringer/parser"
+	"github.com/tnaucoin/stringer/types"
+)
+
+// ScanStats counts what an incremental scan did
+type ScanStats struct {
+	Parsed  int
+	Reused  int
+	Removed int
+	// Changed is set when the entry of the scanned directory was replaced
+	Changed bool
+}
+
+// ScanDirectory parses the actions under root, reusing the results cached
+// for root for files whose content hasn't changed. Only changed and added
+// files are parsed, removed files are dropped, and the entry is replaced
+// when anything differs.
+func (c *Cache) ScanDirectory(root string) (*CacheFile, ScanStats, error) {
+	var stats ScanStats
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, stats, err
+	}
+	target := LocalTarget(absRoot)
+	adopted := c.adopt(target, absRoot)
+	previous, _ := c.Entry(target)
+	// entries of older versions lack what parsing records now
+	if c.Refresh || (previous != nil && previous.Version < CacheVersion) {
+		previous = nil
+	}
+
+	hash, fps, err := hashDirectory(root, previous.fingerprints())
+	if err != nil {
+		return nil, stats, err
+	}
+
+	repoRoot := parser.FindRepoRoot(root)
+	cache := &CacheFile{Hash: hash, Root: absRoot, Files: make(map[string]FileEntry, len(fps))}
+	dirty := previous == nil || adopted
+	for rel, fp := range fps {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if previous != nil {
+			if entry, ok := previous.Files[rel]; ok && entry.Hash == fp.Hash {
+				dirty = dirty || entry.Fingerprint != fp
+				entry.Fingerprint = fp
+				cache.Files[rel] = restore(entry, path, repoRoot)
+				stats.Reused++
+				continue
+			}
+		}
+		action, diags, err := parser.ParseActionFile(path, repoRoot)
+		if err != nil {
+			return nil, stats, err
+		}
+		cache.Files[rel] = FileEntry{Fingerprint: fp, Action: action, Diagnostics: diags}
+		stats.Parsed++
+		dirty = true
+	}
+	if previous != nil {
+		for rel := range previous.Files {
+			if _, ok := fps[rel]; !ok {
+				stats.Removed++
+				dirty = true
+			}
+		}
+	}
+
+	cache.Actions = cache.actions()
+	if dirty {
+		c.SetEntry(target, cache)
+		stats.Changed = true
+	}
+	return cache, stats, nil
+}
+
+// Diagnostics returns the diagnostics of every cached file
+func (c *CacheFile) Diagnostics() []types.Diagnostic {
+	var diags []types.Diagnostic
+	for _, rel := range c.paths() {
+		diags = append(diags, c.Files[rel].Diagnostics...)
+	}
+	return diags
+}
+
+// actions lists the cached actions in the order a directory walk finds
+// their files
+func (c *CacheFile) actions() []types.CompositeAction {
+	var actions []types.CompositeAction
+	for _, rel := range c.paths() {
+		if a := c.Files[rel].Action; a != nil {
+			actions = append(actions, *a)
+		}
+	}
+	return actions
+}
+
+// paths sorts the cached paths directory by directory like filepath.Walk
+func (c *CacheFile) paths() []string {
+	paths := make([]string, 0, len(c.Files))
+	for rel := range c.Files {
+		paths = append(paths, rel)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		a, b := strings.Split(paths[i], "/"), strings.Split(paths[j], "/")
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return paths
+}
+
+// restore fills in what a cache round trip loses, the path the file was
+// read from isn't serialized
+func restore(entry FileEntry, path, repoRoot string) FileEntry {
+	if entry.Action != nil {
+		action := *entry.Action
+		action.Path = path
+		// entries cached before actions carried their root
+		if action.Provenance.Root == "" {
+			action.Provenance.Root = repoRoot
+		}
+		entry.Action = &action
+	}
+	if len(entry.Diagnostics) > 0 {
+		diags := make([]types.Diagnostic, len(entry.Diagnostics))
+		for i, d := range entry.Diagnostics {
+			d.File = path
+			diags[i] = d
+		}
+		entry.Diagnostics = diags
+	}
+	return entry
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const brokenAction = "runs:\n  using: composite\n  steps: [unclosed\n"
+
+func TestScanDirectoryIncremental(t *testing.T) {
+	old := time.Now().Add(-time.Hour)
+	root := t.TempDir()
+	cache := newCache(filepath.Join(t.TempDir(), "cache.json"))
+	writeFile(t, filepath.Join(root, "a", "action.yml"), "name: a\ndescription: d\nruns:\n  using: composite\n  steps: []\n", old)
+	writeFile(t, filepath.Join(root, "b", "action.yml"), "name: b\ndescription: d\nruns:\n  using: composite\n  steps: []\n", old)
+	writeFile(t, filepath.Join(root, "broken", "action.yml"), brokenAction, old)
+	writeFile(t, filepath.Join(root, "config.yml"), "key: value\n", old)
+
+	entry, stats, err := cache.ScanDirectory(root)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if stats != (ScanStats{Parsed: 4, Changed: true}) {
+		t.Errorf("unexpected first scan stats %+v", stats)
+	}
+	if len(entry.Actions) != 2 || entry.Actions[0].Name != "a" || entry.Actions[1].Name != "b" {
+		t.Fatalf("unexpected actions %+v", entry.Actions)
+	}
+	if len(entry.Diagnostics()) != 1 {
+		t.Errorf("expected the broken action to be reported, got %+v", entry.Diagnostics())
+	}
+
+	// nothing changed, nothing is parsed or replaced
+	entry, stats, err = cache.ScanDirectory(root)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if stats != (ScanStats{Reused: 4}) {
+		t.Errorf("expected every file to come from the cache, got %+v", stats)
+	}
+	if len(entry.Actions) != 2 || entry.Actions[0].Path != filepath.Join(root, "a", "action.yml") {
+		t.Errorf("expected cached actions with their path restored, got %+v", entry.Actions)
+	}
+	diags := entry.Diagnostics()
+	if len(diags) != 1 || diags[0].File != filepath.Join(root, "broken", "action.yml") {
+		t.Errorf("expected cached diagnostics, got %+v", diags)
+	}
+
+	// one change, one addition and one removal
+	writeFile(t, filepath.Join(root, "a", "action.yml"), "name: renamed\ndescription: d\nruns:\n  using: composite\n  steps: []\n", old)
+	writeFile(t, filepath.Join(root, "c", "action.yaml"), "name: c\ndescription: d\nruns:\n  using: composite\n  steps: []\n", old)
+	if err := os.RemoveAll(filepath.Join(root, "b")); err != nil {
+		t.Fatalf("failed to remove action: %v", err)
+	}
+	entry, stats, err = cache.ScanDirectory(root)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if stats != (ScanStats{Parsed: 2, Reused: 2, Removed: 1, Changed: true}) {
+		t.Errorf("unexpected incremental stats %+v", stats)
+	}
+	var names []string
+	for _, a := range entry.Actions {
+		names = append(names, a.Name)
+	}
+	if len(names) != 2 || names[0] != "renamed" || names[1] != "c" {
+		t.Errorf("unexpected actions after changes %v", names)
+	}
+
+	// a refresh reparses everything
+	cache.Refresh = true
+	if _, stats, _ = cache.ScanDirectory(root); stats.Parsed != 4 || stats.Reused != 0 {
+		t.Errorf("expected --force to reparse every file, got %+v", stats)
+	}
+}
+
+func TestScanDirectoryOtherRoot(t *testing.T) {
+	old := time.Now().Add(-time.Hour)
+	cache := newCache(filepath.Join(t.TempDir(), "cache.json"))
+	first, second := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(first, "action.yml"), "name: first\ndescription: d\nruns:\n  using: composite\n  steps: []\n", old)
+	writeFile(t, filepath.Join(second, "action.yml"), "name: first\ndescription: d\nruns:\n  using: composite\n  steps: []\n", old)
+
+	if _, _, err := cache.ScanDirectory(first); err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	// identical content under another root is not reused
+	_, stats, err := cache.ScanDirectory(second)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if stats.Parsed != 1 || stats.Reused != 0 {
+		t.Errorf("expected a scan of another root to parse, got %+v", stats)
+	}
+	if len(cache.Targets()) != 2 {
+		t.Errorf("expected both roots to be kept, got %v", cache.Targets())
+	}
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+// Store is a catalog of the actions scanned from many sources, each source
+// keeping the revisions it was saved at
+type Store interface {
+	// Save records a revision of a source, it becomes the one Load and
+	// Query return for the source
+	Save(rev Revision) error
+	// Load returns the actions of the latest revision of every source
+	Load() ([]types.CompositeAction, error)
+	// Query returns the actions of the latest revisions matching q
+	Query(q Query) ([]types.CompositeAction, error)
+	// History lists the revisions saved for source, oldest first
+	History(source string) ([]Revision, error)
+	Close() error
+}
+
+// Revision is a source at a point in time: a repository at a commit, or a
+// local directory with the hash of its contents
+type Revision struct {
+	Source  string
+	Rev     string
+	SavedAt time.Time
+	// Actions are saved with the revision, History leaves them out and
+	// only sets Count
+	Actions []types.CompositeAction
+	Count   int
+}
+
+// Query filters actions, empty fields match everything
+type Query struct {
+	// Name matches action names containing it
+	Name string
+	// Input matches actions declaring an input of that name
+	Input string
+	// Repo matches actions of that repository
+	Repo string
+}
+
+// Matches reports whether a satisfies every field of q, case-insensitively
+func (q Query) Matches(a types.CompositeAction) bool {
+	if q.Name != "" && !strings.Contains(strings.ToLower(a.Name), strings.ToLower(q.Name)) {
+		return false
+	}
+	if q.Repo != "" && !strings.EqualFold(a.Provenance.Repo, q.Repo) {
+		return false
+	}
+	if q.Input != "" {
+		found := false
+		for _, in := range a.Inputs {
+			found = found || strings.EqualFold(in.Name, q.Input)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func filterActions(actions []types.CompositeAction, q Query) []types.CompositeAction {
+	var matched []types.CompositeAction
+	for _, a := range actions {
+		if q.Matches(a) {
+			matched = append(matched, a)
+		}
+	}
+	return matched
+}
+
+// Open opens the store at path, .db files are an embedded database and
+// anything else a JSON cache file
+func Open(path string) (Store, error) {
+	switch filepath.Ext(path) {
+	case ".db", ".bolt":
+		return OpenBolt(path)
+	}
+	return OpenJSON(path)
+}
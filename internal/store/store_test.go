@@ -0,0 +1,131 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+func remoteAction(repo, name string, inputs ...string) types.CompositeAction {
+	a := types.CompositeAction{Name: name, Provenance: types.Provenance{Kind: types.SourceRemote, Repo: repo}}
+	for _, in := range inputs {
+		a.Inputs = append(a.Inputs, types.ActionInput{Name: in})
+	}
+	return a
+}
+
+func names(actions []types.CompositeAction) []string {
+	var n []string
+	for _, a := range actions {
+		n = append(n, a.Name)
+	}
+	return n
+}
+
+func TestStores(t *testing.T) {
+	backends := []struct {
+		name string
+		file string
+		// history reports whether older revisions are kept
+		history bool
+	}{
+		{"json", "catalog.json", false},
+		{"bolt", "catalog.db", true},
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), b.file)
+			s, err := Open(path)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+
+			saves := []Revision{
+				{Source: RepoSource("my-org/deploy"), Rev: "sha1", Actions: []types.CompositeAction{
+					remoteAction("my-org/deploy", "Deploy", "environment"),
+				}},
+				{Source: RepoSource("my-org/tools"), Rev: "sha1", Actions: []types.CompositeAction{
+					remoteAction("my-org/tools", "Setup Node", "node-version"),
+					remoteAction("my-org/tools", "Deploy Docs", "token"),
+				}},
+				// the deploy repo moved on and gained an input
+				{Source: RepoSource("my-org/deploy"), Rev: "sha2", Actions: []types.CompositeAction{
+					remoteAction("my-org/deploy", "Deploy", "environment", "region"),
+				}},
+				// saving the same revision again is a no-op
+				{Source: RepoSource("my-org/deploy"), Rev: "sha2", Actions: nil},
+			}
+			for _, rev := range saves {
+				if err := s.Save(rev); err != nil {
+					t.Fatalf("Save failed: %v", err)
+				}
+			}
+			if err := s.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			s, err = Open(path)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			defer s.Close()
+
+			all, err := s.Load()
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if got := names(all); len(got) != 3 || got[0] != "Deploy" || got[1] != "Setup Node" || got[2] != "Deploy Docs" {
+				t.Errorf("expected the latest revision of every source, got %v", got)
+			}
+
+			queries := []struct {
+				query    Query
+				expected []string
+			}{
+				{Query{Name: "deploy"}, []string{"Deploy", "Deploy Docs"}},
+				{Query{Input: "REGION"}, []string{"Deploy"}},
+				// the older revision is not indexed anymore
+				{Query{Input: "environment", Repo: "my-org/tools"}, nil},
+				{Query{Repo: "my-org/tools", Name: "docs"}, []string{"Deploy Docs"}},
+				{Query{Name: "missing"}, nil},
+			}
+			for _, tt := range queries {
+				actions, err := s.Query(tt.query)
+				if err != nil {
+					t.Fatalf("Query(%+v) failed: %v", tt.query, err)
+				}
+				got := names(actions)
+				if len(got) != len(tt.expected) {
+					t.Errorf("Query(%+v) = %v, expected %v", tt.query, got, tt.expected)
+					continue
+				}
+				for i := range got {
+					if got[i] != tt.expected[i] {
+						t.Errorf("Query(%+v) = %v, expected %v", tt.query, got, tt.expected)
+					}
+				}
+			}
+
+			history, err := s.History(RepoSource("my-org/deploy"))
+			if err != nil {
+				t.Fatalf("History failed: %v", err)
+			}
+			expected := []string{"sha2"}
+			if b.history {
+				expected = []string{"sha1", "sha2"}
+			}
+			if len(history) != len(expected) {
+				t.Fatalf("expected revisions %v, got %+v", expected, history)
+			}
+			for i, rev := range history {
+				if rev.Rev != expected[i] || rev.Count != 1 || rev.SavedAt.IsZero() {
+					t.Errorf("unexpected revision %+v", rev)
+				}
+			}
+			if history, _ := s.History(RepoSource("my-org/unknown")); len(history) != 0 {
+				t.Errorf("expected no history for an unknown source, got %+v", history)
+			}
+		})
+	}
+}
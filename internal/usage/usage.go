@@ -0,0 +1,241 @@
+package usage
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+// Reference is a parsed `uses:` value
+type Reference struct {
+	Raw string
+	// Local references (./path) point into the caller's own repository
+	Local  bool
+	Docker bool
+	Repo   string
+	Path   string
+	Ref    string
+}
+
+// ParseReference splits a `uses:` value into its parts. Supported forms
+// are ./dir, owner/repo@ref, owner/repo/dir@ref and docker://image.
+func ParseReference(uses string) Reference {
+	r := Reference{Raw: uses}
+	switch {
+	case strings.HasPrefix(uses, "docker://"):
+		r.Docker = true
+		return r
+	case strings.HasPrefix(uses, "./"):
+		r.Local = true
+		r.Path = cleanDir(uses)
+		return r
+	}
+
+	target, ref, _ := strings.Cut(uses, "@")
+	r.Ref = ref
+	parts := strings.SplitN(target, "/", 3)
+	if len(parts) < 2 {
+		return r
+	}
+	r.Repo = parts[0] + "/" + parts[1]
+	if len(parts) == 3 {
+		r.Path = cleanDir(parts[2])
+	} else {
+		r.Path = "."
+	}
+	return r
+}
+
+func cleanDir(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+// key identifies an action directory in a scope, see ActionScope
+type key struct {
+	scope string
+	dir   string
+}
+
+func newKey(scope, dir string) key {
+	if dir == "" {
+		dir = "."
+	}
+	return key{scope: strings.ToLower(scope), dir: dir}
+}
+
+func actionKey(a types.CompositeAction) key {
+	return newKey(ActionScope(a), path.Dir(a.Provenance.Path))
+}
+
+// ActionScope is the repository an action's file belongs to, the owner/repo
+// of remote actions or the checkout root of local ones. ./ references made
+// by a file resolve within its scope.
+func ActionScope(a types.CompositeAction) string {
+	if a.Provenance.Kind == types.SourceRemote {
+		return a.Provenance.Repo
+	}
+	return localScope(a.Provenance.Root)
+}
+
+// WorkflowScope is ActionScope for workflows
+func WorkflowScope(w types.Workflow) string {
+	if w.Repo != "" {
+		return w.Repo
+	}
+	return localScope(w.Root)
+}
+
+// localScope can't collide with an owner/repo
+func localScope(root string) string {
+	return "local:" + root
+}
+
+// Consumer is a single step that calls an action
+type Consumer struct {
+	// Source is the file holding the step, prefixed with the repository
+	// for remote files (owner/repo:path)
+	Source string `json:"source"`
+	Repo   string `json:"repo,omitempty"`
+	Path   string `json:"path"`
+	// Job is empty when the caller is a composite action
+	Job  string            `json:"job,omitempty"`
+	Step string            `json:"step"`
+	Line int               `json:"line,omitempty"`
+	Uses string            `json:"uses"`
+	Ref  string            `json:"ref,omitempty"`
+	With map[string]string `json:"with,omitempty"`
+}
+
+type ActionUsage struct {
+	Action    types.CompositeAction `json:"action"`
+	Consumers []Consumer            `json:"consumers"`
+}
+
+type Report struct {
+	Actions []ActionUsage `json:"actions"`
+	// Unresolved are calls to actions that are not in the catalog
+	Unresolved []Consumer `json:"unresolved,omitempty"`
+}
+
+// Index resolves `uses:` references to cataloged actions
+type Index struct {
+	actions map[key]int
+	catalog []types.CompositeAction
+}
+
+func NewIndex(catalog []types.CompositeAction) *Index {
+	idx := &Index{actions: map[key]int{}, catalog: catalog}
+	for i, a := range catalog {
+		k := actionKey(a)
+		if _, exists := idx.actions[k]; !exists {
+			idx.actions[k] = i
+		}
+	}
+	return idx
+}
+
+// Resolve returns the position in the catalog of the action uses points
+// to, scope is the ActionScope or WorkflowScope of the calling file
+func (idx *Index) Resolve(uses, scope string) (int, bool) {
+	ref := ParseReference(uses)
+	switch {
+	case ref.Docker:
+		return 0, false
+	case ref.Local:
+		i, ok := idx.actions[newKey(scope, ref.Path)]
+		return i, ok
+	case ref.Repo != "":
+		i, ok := idx.actions[newKey(ref.Repo, ref.Path)]
+		return i, ok
+	}
+	return 0, false
+}
+
+// BuildReport finds every step in the workflows and in the cataloged
+// composite actions themselves that calls a cataloged action
+func BuildReport(catalog []types.CompositeAction, workflows []types.Workflow) Report {
+	idx := NewIndex(catalog)
+	report := Report{Actions: make([]ActionUsage, len(catalog))}
+	for i, a := range catalog {
+		report.Actions[i].Action = a
+	}
+
+	record := func(c Consumer, scope string) {
+		if i, ok := idx.Resolve(c.Uses, scope); ok {
+			report.Actions[i].Consumers = append(report.Actions[i].Consumers, c)
+			return
+		}
+		report.Unresolved = append(report.Unresolved, c)
+	}
+
+	for _, w := range workflows {
+		for _, job := range w.Jobs {
+			for n, step := range job.Steps {
+				if step.Uses == "" {
+					continue
+				}
+				record(newConsumer(w.Repo, w.Path, job.ID, n, step), WorkflowScope(w))
+			}
+		}
+	}
+
+	for _, a := range catalog {
+		repo := ""
+		if a.Provenance.Kind == types.SourceRemote {
+			repo = a.Provenance.Repo
+		}
+		for n, step := range a.Runs.Steps {
+			if step.Uses == "" {
+				continue
+			}
+			record(newConsumer(repo, a.Provenance.Path, "", n, step), ActionScope(a))
+		}
+	}
+
+	for i := range report.Actions {
+		sortConsumers(report.Actions[i].Consumers)
+	}
+	sortConsumers(report.Unresolved)
+	return report
+}
+
+func newConsumer(repo, file, job string, index int, step types.Step) Consumer {
+	source := file
+	if repo != "" {
+		source = repo + ":" + file
+	}
+	return Consumer{
+		Source: source,
+		Repo:   repo,
+		Path:   file,
+		Job:    job,
+		Step:   StepLabel(index, step),
+		Line:   step.Line,
+		Uses:   step.Uses,
+		Ref:    ParseReference(step.Uses).Ref,
+		With:   step.With,
+	}
+}
+
+// StepLabel names a step by its name, its id or its position
+func StepLabel(index int, step types.Step) string {
+	switch {
+	case step.Name != "":
+		return step.Name
+	case step.ID != "":
+		return step.ID
+	}
+	return "#" + strconv.Itoa(index+1)
+}
+
+func sortConsumers(consumers []Consumer) {
+	sort.SliceStable(consumers, func(i, j int) bool {
+		if consumers[i].Source != consumers[j].Source {
+			return consumers[i].Source < consumers[j].Source
+		}
+		return consumers[i].Line < consumers[j].Line
+	})
+}
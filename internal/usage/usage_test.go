@@ -0,0 +1,128 @@
+package usage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		uses     string
+		expected Reference
+	}{
+		{"./.github/actions/greet", Reference{Raw: "./.github/actions/greet", Local: true, Path: ".github/actions/greet"}},
+		{"actions/checkout@v4", Reference{Raw: "actions/checkout@v4", Repo: "actions/checkout", Path: ".", Ref: "v4"}},
+		{"my-org/actions/greet@main", Reference{Raw: "my-org/actions/greet@main", Repo: "my-org/actions", Path: "greet", Ref: "main"}},
+		{"my-org/actions/a/b/@v1", Reference{Raw: "my-org/actions/a/b/@v1", Repo: "my-org/actions", Path: "a/b", Ref: "v1"}},
+		{"docker://alpine:3.20", Reference{Raw: "docker://alpine:3.20", Docker: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uses, func(t *testing.T) {
+			if got := ParseReference(tt.uses); got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	catalog := []types.CompositeAction{
+		{
+			Name: "Greet",
+			Provenance: types.Provenance{
+				Kind: types.SourceRemote,
+				Repo: "My-Org/actions",
+				Ref:  "v1",
+				Path: "greet/action.yml",
+			},
+		},
+		{
+			Name: "Local Build",
+			Provenance: types.Provenance{
+				Kind: types.SourceLocal,
+				Path: ".github/actions/build/action.yml",
+			},
+			Runs: types.Runs{
+				Using: "composite",
+				Steps: []types.Step{
+					{Uses: "my-org/actions/greet@v2", Line: 9},
+				},
+			},
+		},
+	}
+
+	workflows := []types.Workflow{
+		{
+			Path: ".github/workflows/ci.yml",
+			Jobs: []types.Job{
+				{
+					ID: "build",
+					Steps: []types.Step{
+						{Uses: "actions/checkout@v4", Line: 7},
+						{Name: "Build", Uses: "./.github/actions/build", Line: 8},
+						{ID: "greet", Uses: "my-org/actions/greet@v1", With: map[string]string{"name": "ci"}, Line: 10},
+						{Run: "echo done", Line: 13},
+					},
+				},
+			},
+		},
+		{
+			// ./ references in another repository must not resolve to the
+			// local tree
+			Path: ".github/workflows/other.yml",
+			Repo: "my-org/service",
+			Jobs: []types.Job{
+				{ID: "build", Steps: []types.Step{{Uses: "./.github/actions/build", Line: 5}}},
+			},
+		},
+	}
+
+	report := BuildReport(catalog, workflows)
+	if len(report.Actions) != 2 {
+		t.Fatalf("expected 2 actions in report, got %d", len(report.Actions))
+	}
+
+	greet := report.Actions[0].Consumers
+	expectedGreet := []Consumer{
+		{Source: ".github/actions/build/action.yml", Path: ".github/actions/build/action.yml", Step: "#1", Line: 9, Uses: "my-org/actions/greet@v2", Ref: "v2"},
+		{Source: ".github/workflows/ci.yml", Path: ".github/workflows/ci.yml", Job: "build", Step: "greet", Line: 10, Uses: "my-org/actions/greet@v1", Ref: "v1", With: map[string]string{"name": "ci"}},
+	}
+	if !reflect.DeepEqual(greet, expectedGreet) {
+		t.Errorf("expected greet consumers %+v, got %+v", expectedGreet, greet)
+	}
+
+	build := report.Actions[1].Consumers
+	if len(build) != 1 || build[0].Step != "Build" || build[0].Line != 8 {
+		t.Errorf("unexpected local build consumers %+v", build)
+	}
+
+	if len(report.Unresolved) != 2 {
+		t.Fatalf("expected 2 unresolved calls, got %+v", report.Unresolved)
+	}
+	if report.Unresolved[0].Uses != "actions/checkout@v4" || report.Unresolved[1].Source != "my-org/service:.github/workflows/other.yml" {
+		t.Errorf("unexpected unresolved calls %+v", report.Unresolved)
+	}
+}
+
+func TestBuildReportLocalRoots(t *testing.T) {
+	// two checkouts cataloged together, both with an act directory
+	local := func(root, name string) types.CompositeAction {
+		return types.CompositeAction{
+			Name:       name,
+			Provenance: types.Provenance{Kind: types.SourceLocal, Root: root, Path: "act/action.yml", Uses: "./act"},
+		}
+	}
+	catalog := []types.CompositeAction{local("/src/ra", "A"), local("/src/rb", "B")}
+	workflows := []types.Workflow{{
+		Path: ".github/workflows/ci.yml",
+		Root: "/src/rb",
+		Jobs: []types.Job{{ID: "ci", Steps: []types.Step{{Uses: "./act", Line: 6}}}},
+	}}
+
+	report := BuildReport(catalog, workflows)
+	if len(report.Actions[0].Consumers) != 0 || len(report.Actions[1].Consumers) != 1 {
+		t.Errorf("expected ./act to resolve within the caller's root, got %+v", report.Actions)
+	}
+}
@@ -4,71 +4,335 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/tnaucoin/stringer/types"
 	"gopkg.in/yaml.v3"
 )
 
-func ParseCompositeActions(root string) ([]types.CompositeAction, error) {
+// ParseActions scans a directory for GitHub Actions of every kind. Files
+// that look like actions but fail to parse are reported as diagnostics,
+// as are schema violations in the ones that do.
+func ParseActions(root string) ([]types.CompositeAction, []types.Diagnostic, error) {
 	var actions []types.CompositeAction
+	var diags []types.Diagnostic
+	repoRoot := FindRepoRoot(root)
 	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if filepath.Ext(path) == ".yml" || filepath.Ext(path) == ".yaml" {
-			data, err := os.ReadFile(path)
+		if info.IsDir() {
+			if path != root && IsIgnoredDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if IsYAMLFile(path) {
+			action, fileDiags, err := ParseActionFile(path, repoRoot)
 			if err != nil {
 				return err
 			}
-			action, err := ParseCompositeActionFromBytes(data, path)
-			if err != nil {
-				// TODO: log errors
-			} else {
-				actions = append(actions, action)
+			diags = append(diags, fileDiags...)
+			if action != nil {
+				actions = append(actions, *action)
 			}
 		}
 		return nil
 	})
-	return actions, err
+	return actions, diags, err
+}
+
+// ParseActionFile parses a single file of a local checkout rooted at
+// repoRoot. The action is nil when the file is not one, the error is only
+// set when the file can't be read.
+func ParseActionFile(path, repoRoot string) (*types.CompositeAction, []types.Diagnostic, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	action, warnings, err := parseAction(data, path)
+	if err != nil {
+		if d, ok := diagnosticFor(path, err); ok {
+			return nil, []types.Diagnostic{d}, nil
+		}
+		return nil, nil, nil
+	}
+	diags := append(warnings, ValidateAction(data, path)...)
+	action.Provenance = localProvenance(repoRoot, path)
+	return &action, diags, nil
+}
+
+// ignoredDirs are never walked, they hold VCS data or third party code
+// that is not ours to catalog and can be huge in monorepos
+var ignoredDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// IsIgnoredDir reports whether directories with this name are skipped
+// when walking a tree
+func IsIgnoredDir(name string) bool {
+	return ignoredDirs[name]
+}
+
+// IsYAMLFile reports whether path is a file the parser reads
+func IsYAMLFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// ParseCompositeActions scans a directory for composite GitHub Actions,
+// actions of other kinds are reported as notices
+func ParseCompositeActions(root string) ([]types.CompositeAction, []types.Diagnostic, error) {
+	actions, diags, err := ParseActions(root)
+	for _, a := range actions {
+		if a.Kind != types.KindComposite {
+			diags = append(diags, types.Diagnostic{
+				File:     a.Path,
+				Severity: types.SeverityNotice,
+				Code:     ReasonNotComposite,
+				Message:  fmt.Sprintf("skipped %s action", a.Kind),
+			})
+		}
+	}
+	return FilterKind(actions, types.KindComposite), diags, err
+}
+
+// FilterKind returns the actions of the given kind
+func FilterKind(actions []types.CompositeAction, kind types.ActionKind) []types.CompositeAction {
+	var filtered []types.CompositeAction
+	for _, a := range actions {
+		if a.Kind == kind {
+			filtered = append(filtered, a)
+		}
+	}
+	return filtered
 }
 
-// ParseCompositeActions scans a directory for composite GitHub Actions
+// ParseCompositeActionFromBytes parses an action file and rejects every
+// kind but composite
 func ParseCompositeActionFromBytes(data []byte, path string) (types.CompositeAction, error) {
+	action, err := ParseActionFromBytes(data, path)
+	if err != nil {
+		return types.CompositeAction{}, err
+	}
+	if action.Kind != types.KindComposite {
+		return types.CompositeAction{}, &ParseError{Reason: ReasonNotComposite, Message: "not a composite action"}
+	}
+	return action, nil
+}
+
+// ParseActionFromBytes parses a composite, javascript or docker action
+// file, failures are returned as *ParseError
+func ParseActionFromBytes(data []byte, path string) (types.CompositeAction, error) {
+	action, _, err := parseAction(data, path)
+	return action, err
+}
+
+// parseAction is ParseActionFromBytes that also returns the warnings for
+// problems that don't stop the action from being usable
+func parseAction(data []byte, path string) (types.CompositeAction, []types.Diagnostic, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return types.CompositeAction{}, nil, yamlError(ReasonInvalidYAML, "invalid yaml file", err)
+	}
+	root := documentRoot(&doc)
+	if root.Kind != yaml.MappingNode {
+		return types.CompositeAction{}, nil, newParseError(ReasonNotAnAction, root, "not an action, expected a map")
+	}
 	var raw map[string]any
-	if err := yaml.Unmarshal(data, &raw); err != nil {
-		return types.CompositeAction{}, fmt.Errorf("invalid yaml file") // skip invalid YAML
+	if err := root.Decode(&raw); err != nil {
+		return types.CompositeAction{}, nil, yamlError(ReasonInvalidYAML, "invalid yaml file", err)
 	}
 
-	runs, ok := raw["runs"].(map[string]any)
-	if !ok || runs["using"] != "composite" {
-		return types.CompositeAction{}, fmt.Errorf("not a composite action")
+	runsNode := mappingValue(root, "runs")
+	if runsNode == nil {
+		return types.CompositeAction{}, nil, newParseError(ReasonNotAnAction, root, "not an action, missing runs")
+	}
+	var runs types.Runs
+	if err := runsNode.Decode(&runs); err != nil {
+		return types.CompositeAction{}, nil, yamlError(ReasonInvalidField, "invalid runs", err)
+	}
+	if steps := mappingValue(runsNode, "steps"); steps != nil {
+		for i, n := range steps.Content {
+			if i < len(runs.Steps) {
+				setStepPositions(&runs.Steps[i], n)
+			}
+		}
+	}
+	kind, ok := types.KindFromUsing(runs.Using)
+	if !ok {
+		if runs.Using == "" {
+			return types.CompositeAction{}, nil, newParseError(ReasonMissingField, runsNode, "runs.using is required")
+		}
+		return types.CompositeAction{}, nil, newParseError(ReasonUnsupportedUsing, mappingValue(runsNode, "using"), fmt.Sprintf("unsupported runs.using %q", runs.Using))
+	}
+	if kind == types.KindJavaScript && runs.Main == "" {
+		return types.CompositeAction{}, nil, newParseError(ReasonMissingField, runsNode, "javascript action must set runs.main")
+	}
+	if kind == types.KindDocker && runs.Image == "" {
+		return types.CompositeAction{}, nil, newParseError(ReasonMissingField, runsNode, "docker action must set runs.image")
 	}
 
+	// GitHub requires name and description, but older actions often lack
+	// them and still run fine, so keep them in the catalog with a warning
+	var warnings []types.Diagnostic
 	name := getString(raw["name"])
+	if name == "" {
+		name = fallbackName(path)
+		warnings = append(warnings, missingFieldWarning(path, root, fmt.Sprintf("action has no name, using %q", name)))
+	}
 	description := getString(raw["description"])
-	// TODO: name and desc, are optional on valid composite actions
-	// should handle it, but for now treat it as invalid
-	if name == "" || description == "" {
-		return types.CompositeAction{}, fmt.Errorf("the composite action must have a name, and description")
+	if description == "" {
+		warnings = append(warnings, missingFieldWarning(path, root, "action has no description"))
 	}
 
 	action := types.CompositeAction{
+		Kind:        kind,
 		Name:        name,
 		Description: description,
+		Runs:        runs,
 		Path:        path,
 	}
+	action.Inputs = parseInputs(mappingValue(root, "inputs"))
+	action.Outputs = parseOutputs(mappingValue(root, "outputs"))
 
-	if v, ok := raw["inputs"].(map[string]any); ok {
-		fmt.Println("Found input...")
-		action.Inputs = v
+	return action, warnings, nil
+}
+
+// fallbackName names an action after the directory holding its file
+func fallbackName(path string) string {
+	dir := filepath.Base(filepath.Dir(path))
+	if dir == "." || dir == string(filepath.Separator) {
+		return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 	}
+	return dir
+}
 
-	if v, ok := raw["outputs"].(map[string]any); ok {
-		fmt.Println("found output...")
-		action.Outputs = v
+func missingFieldWarning(path string, node *yaml.Node, message string) types.Diagnostic {
+	return types.Diagnostic{
+		File:     path,
+		Line:     node.Line,
+		Column:   node.Column,
+		Severity: types.SeverityWarning,
+		Code:     ReasonMissingField,
+		Message:  message,
 	}
+}
 
-	return action, nil
+// parseInputs reads the inputs mapping keeping the declaration order
+func parseInputs(node *yaml.Node) types.ActionInputs {
+	var inputs types.ActionInputs
+	eachMappingPair(node, func(key, value *yaml.Node) {
+		input := types.ActionInput{Name: key.Value, Line: key.Line}
+		eachMappingPair(value, func(k, v *yaml.Node) {
+			switch k.Value {
+			case "description":
+				input.Description = scalarValue(v)
+			case "required":
+				input.Required = strings.EqualFold(scalarValue(v), "true")
+			case "default":
+				input.Default = scalarValue(v)
+			case "deprecationMessage":
+				input.DeprecationMessage = scalarValue(v)
+			}
+		})
+		inputs = append(inputs, input)
+	})
+	return inputs
+}
+
+// parseOutputs reads the outputs mapping keeping the declaration order
+func parseOutputs(node *yaml.Node) types.ActionOutputs {
+	var outputs types.ActionOutputs
+	eachMappingPair(node, func(key, value *yaml.Node) {
+		output := types.ActionOutput{Name: key.Value, Line: key.Line}
+		eachMappingPair(value, func(k, v *yaml.Node) {
+			switch k.Value {
+			case "description":
+				output.Description = scalarValue(v)
+			case "value":
+				output.Value = scalarValue(v)
+			}
+		})
+		outputs = append(outputs, output)
+	})
+	return outputs
+}
+
+// localProvenance describes an action file found on disk, paths are made
+// relative to the repository root so the `uses:` reference is usable
+func localProvenance(repoRoot, path string) types.Provenance {
+	rel := path
+	if abs, err := filepath.Abs(path); err == nil {
+		if r, err := filepath.Rel(repoRoot, abs); err == nil {
+			rel = r
+		}
+	}
+	rel = filepath.ToSlash(rel)
+	return types.Provenance{
+		Kind: types.SourceLocal,
+		Root: repoRoot,
+		Path: rel,
+		Uses: types.UsesReference("", "", rel),
+	}
+}
+
+// FindRepoRoot walks up from dir to the closest directory containing .git,
+// falling back to dir itself when it is not inside a git checkout
+func FindRepoRoot(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return abs
+		}
+	}
+}
+
+// documentRoot unwraps the document node yaml.Unmarshal produces
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0]
+	}
+	return doc
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	var found *yaml.Node
+	eachMappingPair(node, func(k, v *yaml.Node) {
+		if found == nil && k.Value == key {
+			found = v
+		}
+	})
+	return found
+}
+
+// eachMappingPair calls fn for every key/value pair of a mapping node, it
+// is a no-op for nil or non-mapping nodes
+func eachMappingPair(node *yaml.Node, fn func(key, value *yaml.Node)) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fn(node.Content[i], node.Content[i+1])
+	}
+}
+
+// scalarValue returns the text of a scalar node, null and non-scalar nodes
+// yield an empty string
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+		return ""
+	}
+	return node.Value
 }
 
 func getString(v any) string {
@@ -3,6 +3,7 @@ package parser
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/tnaucoin/stringer/types"
@@ -41,7 +42,7 @@ runs:
 runs:
   using: composite
   steps:
-    - run: echo "hi`,
+    - run: "echo hi`, // unterminated quoted scalar
 			expected: 0,
 		},
 		{
@@ -70,7 +71,7 @@ runs:
     - run: echo "hi"
       shell: bash
 `,
-			expected: 0, // Currently implementation requires name and description
+			expected: 1, // name and description are optional, only warned about
 		},
 		{
 			name: "missing name only",
@@ -82,7 +83,7 @@ runs:
     - run: echo "hi"
       shell: bash
 `,
-			expected: 0, // Currently implementation requires both name and description
+			expected: 1, // name and description are optional, only warned about
 		},
 		{
 			name: "missing description only",
@@ -94,7 +95,7 @@ runs:
     - run: echo "hi"
       shell: bash
 `,
-			expected: 0, // Currently implementation requires both name and description
+			expected: 1, // name and description are optional, only warned about
 		},
 		{
 			name: "with inputs but no outputs",
@@ -142,7 +143,7 @@ runs:
 				t.Fatalf("failed to write test file: %v", err)
 			}
 
-			actions, err := ParseCompositeActions(tmpDir)
+			actions, _, err := ParseCompositeActions(tmpDir)
 			if err != nil {
 				t.Fatalf("ParseCompositeActions returned error: %v", err)
 			}
@@ -184,8 +185,8 @@ runs:
 				Name:        "Test Action",
 				Description: "A test composite action",
 				Path:        "test-path",
-				Inputs:      map[string]any{"name": map[string]any{"description": "Name to greet", "required": true}},
-				Outputs:     map[string]any{"greeting": map[string]any{"description": "Greeting", "value": "${{ steps.greet.outputs.greeting }}"}},
+				Inputs:      types.ActionInputs{{Name: "name", Description: "Name to greet", Required: true, Line: 5}},
+				Outputs:     types.ActionOutputs{{Name: "greeting", Description: "Greeting", Value: "${{ steps.greet.outputs.greeting }}", Line: 9}},
 			},
 			isError: false,
 		},
@@ -230,8 +231,283 @@ runs:
 				if action.Path != tt.expected.Path {
 					t.Errorf("expected path %q, got %q", tt.expected.Path, action.Path)
 				}
-				// Note: We're not doing deep comparison of inputs/outputs maps here
-				// as that would require more complex test setup
+				if !reflect.DeepEqual(action.Inputs, tt.expected.Inputs) {
+					t.Errorf("expected inputs %+v, got %+v", tt.expected.Inputs, action.Inputs)
+				}
+				if !reflect.DeepEqual(action.Outputs, tt.expected.Outputs) {
+					t.Errorf("expected outputs %+v, got %+v", tt.expected.Outputs, action.Outputs)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCompositeActionsProvenance(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repoDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git dir: %v", err)
+	}
+	actionDir := filepath.Join(repoDir, ".github", "actions", "greet")
+	if err := os.MkdirAll(actionDir, 0755); err != nil {
+		t.Fatalf("failed to create action dir: %v", err)
+	}
+	content := `
+name: "Greet"
+description: "Says hello"
+runs:
+  using: "composite"
+  steps:
+    - run: echo "hello"
+      shell: bash
+`
+	if err := os.WriteFile(filepath.Join(actionDir, "action.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	// scanning a subdirectory still yields paths relative to the repo root
+	actions, _, err := ParseCompositeActions(filepath.Join(repoDir, ".github"))
+	if err != nil {
+		t.Fatalf("ParseCompositeActions returned error: %v", err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("expected 1 action, got %d", len(actions))
+	}
+
+	expected := types.Provenance{
+		Kind: types.SourceLocal,
+		Root: repoDir,
+		Path: ".github/actions/greet/action.yml",
+		Uses: "./.github/actions/greet",
+	}
+	if actions[0].Provenance != expected {
+		t.Errorf("expected provenance %+v, got %+v", expected, actions[0].Provenance)
+	}
+}
+
+func TestParseInputsAndOutputs(t *testing.T) {
+	content := `
+name: "Deploy"
+description: "Deploys a service"
+inputs:
+  service:
+    description: "Service to deploy"
+    required: true
+  replicas:
+    description: "Replica count"
+    required: "false"
+    default: 3
+  region:
+    default: us-east-1
+    deprecationMessage: "Use the regions input instead"
+  regions:
+outputs:
+  url:
+    description: "Deployed URL"
+    value: ${{ steps.deploy.outputs.url }}
+  id:
+    value: ${{ steps.deploy.outputs.id }}
+runs:
+  using: "composite"
+  steps:
+    - id: deploy
+      run: ./deploy.sh
+      shell: bash
+`
+	action, err := ParseCompositeActionFromBytes([]byte(content), "action.yml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedInputs := types.ActionInputs{
+		{Name: "service", Description: "Service to deploy", Required: true, Line: 5},
+		{Name: "replicas", Description: "Replica count", Default: "3", Line: 8},
+		{Name: "region", Default: "us-east-1", DeprecationMessage: "Use the regions input instead", Line: 12},
+		{Name: "regions", Line: 15},
+	}
+	if !reflect.DeepEqual(action.Inputs, expectedInputs) {
+		t.Errorf("expected inputs %+v, got %+v", expectedInputs, action.Inputs)
+	}
+
+	expectedOutputs := types.ActionOutputs{
+		{Name: "url", Description: "Deployed URL", Value: "${{ steps.deploy.outputs.url }}", Line: 17},
+		{Name: "id", Value: "${{ steps.deploy.outputs.id }}", Line: 20},
+	}
+	if !reflect.DeepEqual(action.Outputs, expectedOutputs) {
+		t.Errorf("expected outputs %+v, got %+v", expectedOutputs, action.Outputs)
+	}
+}
+
+func TestParseCompositeActionSteps(t *testing.T) {
+	content := `
+name: "Build"
+description: "Checks out and builds"
+runs:
+  using: "composite"
+  steps:
+    - uses: actions/checkout@v4
+      with:
+        fetch-depth: 0
+    - name: Build
+      id: build
+      if: ${{ inputs.skip != 'true' }}
+      run: make build
+      shell: bash
+      working-directory: ./src
+      continue-on-error: true
+      env:
+        GOFLAGS: -mod=mod
+    - uses: my-org/actions/notify@v1
+`
+	action, err := ParseCompositeActionFromBytes([]byte(content), "action.yml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []types.Step{
+		{
+			Uses:      "actions/checkout@v4",
+			With:      map[string]string{"fetch-depth": "0"},
+			Line:      7,
+			WithLines: map[string]int{"fetch-depth": 9},
+		},
+		{
+			ID:               "build",
+			Name:             "Build",
+			If:               "${{ inputs.skip != 'true' }}",
+			Run:              "make build",
+			Shell:            "bash",
+			WorkingDirectory: "./src",
+			ContinueOnError:  "true",
+			Env:              map[string]string{"GOFLAGS": "-mod=mod"},
+			Line:             10,
+		},
+		{
+			Uses: "my-org/actions/notify@v1",
+			Line: 19,
+		},
+	}
+	if action.Runs.Using != "composite" {
+		t.Errorf("expected runs.using composite, got %q", action.Runs.Using)
+	}
+	if !reflect.DeepEqual(action.Runs.Steps, expected) {
+		t.Errorf("expected steps %+v, got %+v", expected, action.Runs.Steps)
+	}
+
+	calls := action.Calls()
+	if !reflect.DeepEqual(calls, []string{"actions/checkout@v4", "my-org/actions/notify@v1"}) {
+		t.Errorf("unexpected calls %v", calls)
+	}
+}
+
+func TestParseCompositeActionInvalidSteps(t *testing.T) {
+	content := `
+name: "Broken"
+description: "Steps is not a list"
+runs:
+  using: "composite"
+  steps:
+    run: echo "hi"
+`
+	if _, err := ParseCompositeActionFromBytes([]byte(content), "action.yml"); err == nil {
+		t.Errorf("expected error for non-list steps")
+	}
+}
+
+func TestParseActionFromBytesKinds(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		expectedKind types.ActionKind
+		expectedRuns types.Runs
+		isError      bool
+	}{
+		{
+			name: "javascript action",
+			content: `
+name: "JS Action"
+description: "Runs on node"
+runs:
+  using: "node20"
+  pre: "setup.js"
+  main: "dist/index.js"
+  post: "cleanup.js"
+  post-if: "always()"
+`,
+			expectedKind: types.KindJavaScript,
+			expectedRuns: types.Runs{Using: "node20", Pre: "setup.js", Main: "dist/index.js", Post: "cleanup.js", PostIf: "always()"},
+		},
+		{
+			name: "docker action",
+			content: `
+name: "Docker Action"
+description: "Runs in a container"
+runs:
+  using: "docker"
+  image: "Dockerfile"
+  entrypoint: "/entrypoint.sh"
+  args:
+    - ${{ inputs.who }}
+    - --verbose
+  env:
+    MODE: ci
+`,
+			expectedKind: types.KindDocker,
+			expectedRuns: types.Runs{Using: "docker", Image: "Dockerfile", Entrypoint: "/entrypoint.sh", Args: []string{"${{ inputs.who }}", "--verbose"}, Env: map[string]string{"MODE": "ci"}},
+		},
+		{
+			name: "javascript action without main",
+			content: `
+name: "Broken JS"
+description: "Missing main"
+runs:
+  using: "node16"
+`,
+			isError: true,
+		},
+		{
+			name: "docker action without image",
+			content: `
+name: "Broken Docker"
+description: "Missing image"
+runs:
+  using: "docker"
+`,
+			isError: true,
+		},
+		{
+			name: "unsupported using",
+			content: `
+name: "Unknown"
+description: "Unknown runtime"
+runs:
+  using: "python3"
+`,
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, err := ParseActionFromBytes([]byte(tt.content), "action.yml")
+			if tt.isError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if action.Kind != tt.expectedKind {
+				t.Errorf("expected kind %q, got %q", tt.expectedKind, action.Kind)
+			}
+			if !reflect.DeepEqual(action.Runs, tt.expectedRuns) {
+				t.Errorf("expected runs %+v, got %+v", tt.expectedRuns, action.Runs)
+			}
+
+			// the composite-only entry point keeps rejecting other kinds
+			if _, err := ParseCompositeActionFromBytes([]byte(tt.content), "action.yml"); err == nil {
+				t.Errorf("expected ParseCompositeActionFromBytes to reject %s", tt.expectedKind)
 			}
 		})
 	}
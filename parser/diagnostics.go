@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/tnaucoin/stringer/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Reasons an action file could not be parsed
+const (
+	ReasonInvalidYAML      types.DiagnosticCode = "invalid-yaml"
+	ReasonNotAnAction      types.DiagnosticCode = "not-an-action"
+	ReasonNotComposite     types.DiagnosticCode = "not-composite"
+	ReasonUnsupportedUsing types.DiagnosticCode = "unsupported-using"
+	ReasonMissingField     types.DiagnosticCode = "missing-field"
+	ReasonInvalidField     types.DiagnosticCode = "invalid-field"
+)
+
+// ParseError is returned by the parse functions with the reason a file
+// was rejected and, when known, where in the file the problem is
+type ParseError struct {
+	Reason  types.DiagnosticCode
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return e.Message
+}
+
+func newParseError(reason types.DiagnosticCode, node *yaml.Node, message string) *ParseError {
+	e := &ParseError{Reason: reason, Message: message}
+	if node != nil {
+		e.Line = node.Line
+		e.Column = node.Column
+	}
+	return e
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// yamlError wraps an error from the yaml package, which only reports
+// positions inside its message
+func yamlError(reason types.DiagnosticCode, message string, err error) *ParseError {
+	e := &ParseError{Reason: reason, Message: message + ": " + err.Error()}
+	if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+	}
+	return e
+}
+
+// isActionFileName reports whether path is named like an action metadata
+// file, only those are expected to parse as actions
+func isActionFileName(path string) bool {
+	base := filepath.Base(path)
+	return base == "action.yml" || base == "action.yaml"
+}
+
+// diagnosticFor turns a parse failure of the file at path into a
+// diagnostic. YAML files that aren't named action.yml/action.yaml and
+// don't look like actions are ignored, a repo holds plenty of those.
+func diagnosticFor(path string, err error) (types.Diagnostic, bool) {
+	d := types.Diagnostic{
+		File:     path,
+		Severity: types.SeverityError,
+		Code:     ReasonInvalidField,
+		Message:  err.Error(),
+	}
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		d.Code = pe.Reason
+		d.Line = pe.Line
+		d.Column = pe.Column
+	}
+
+	if isActionFileName(path) {
+		return d, true
+	}
+	switch d.Code {
+	case ReasonNotAnAction:
+		return d, false
+	case ReasonInvalidYAML:
+		// templated YAML (helm charts etc.) often doesn't parse on its own
+		d.Severity = types.SeverityNotice
+	}
+	return d, true
+}
+
+// Summary counts diagnostics by severity
+func Summary(diags []types.Diagnostic) map[types.Severity]int {
+	counts := map[types.Severity]int{}
+	for _, d := range diags {
+		counts[d.Severity]++
+	}
+	return counts
+}
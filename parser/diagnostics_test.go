@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+func TestParseActionFromBytesErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		expectedReason types.DiagnosticCode
+		expectedLine   int
+	}{
+		{
+			name:           "invalid yaml",
+			content:        "name: x\nruns:\n  using: composite\n  steps:\n    - run: \"echo hi\n",
+			expectedReason: ReasonInvalidYAML,
+			expectedLine:   5,
+		},
+		{
+			name:           "no runs",
+			content:        "name: x\ndescription: y\n",
+			expectedReason: ReasonNotAnAction,
+			expectedLine:   1,
+		},
+		{
+			name:           "unsupported using",
+			content:        "name: x\ndescription: y\nruns:\n  using: python\n",
+			expectedReason: ReasonUnsupportedUsing,
+			expectedLine:   4,
+		},
+		{
+			name:           "missing using",
+			content:        "name: x\ndescription: y\nruns:\n  steps: []\n",
+			expectedReason: ReasonMissingField,
+			expectedLine:   4,
+		},
+		{
+			name:           "invalid steps",
+			content:        "name: x\ndescription: y\nruns:\n  using: composite\n  steps: hello\n",
+			expectedReason: ReasonInvalidField,
+			expectedLine:   5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseActionFromBytes([]byte(tt.content), "action.yml")
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("expected *ParseError, got %v", err)
+			}
+			if pe.Reason != tt.expectedReason {
+				t.Errorf("expected reason %q, got %q", tt.expectedReason, pe.Reason)
+			}
+			if pe.Line != tt.expectedLine {
+				t.Errorf("expected line %d, got %d", tt.expectedLine, pe.Line)
+			}
+		})
+	}
+}
+
+func TestParseActionsDiagnostics(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		// broken action files are errors
+		"broken/action.yml": "runs:\n  using: composite\n  steps: [unclosed\n",
+		"js/action.yaml":    "name: x\ndescription: y\nruns:\n  using: node20\n",
+		// unrelated YAML is ignored, templated YAML is only a notice
+		"config.yml":              "key: value\n",
+		"chart/templates/svc.yml": "{{- if .Values.enabled }}\nkind: Service\n",
+		// a valid action produces no diagnostics
+		"ok/action.yml": "name: x\ndescription: y\nruns:\n  using: composite\n  steps: []\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	actions, diags, err := ParseActions(tmpDir)
+	if err != nil {
+		t.Fatalf("ParseActions returned error: %v", err)
+	}
+	if len(actions) != 1 {
+		t.Errorf("expected 1 action, got %d", len(actions))
+	}
+
+	got := map[string]types.Diagnostic{}
+	for _, d := range diags {
+		rel, _ := filepath.Rel(tmpDir, d.File)
+		got[filepath.ToSlash(rel)] = d
+	}
+	expected := map[string]struct {
+		severity types.Severity
+		code     types.DiagnosticCode
+	}{
+		"broken/action.yml":       {types.SeverityError, ReasonInvalidYAML},
+		"js/action.yaml":          {types.SeverityError, ReasonMissingField},
+		"chart/templates/svc.yml": {types.SeverityNotice, ReasonInvalidYAML},
+	}
+	if len(got) != len(expected) {
+		t.Errorf("expected %d diagnostics, got %+v", len(expected), diags)
+	}
+	for file, e := range expected {
+		d, ok := got[file]
+		if !ok {
+			t.Errorf("expected a diagnostic for %s", file)
+			continue
+		}
+		if d.Severity != e.severity || d.Code != e.code {
+			t.Errorf("%s: expected %s %s, got %s %s", file, e.severity, e.code, d.Severity, d.Code)
+		}
+	}
+
+	counts := Summary(diags)
+	if counts[types.SeverityError] != 2 || counts[types.SeverityNotice] != 1 {
+		t.Errorf("unexpected summary %v", counts)
+	}
+}
+
+func TestParseActionsMissingNameAndDescription(t *testing.T) {
+	tmpDir := t.TempDir()
+	actionDir := filepath.Join(tmpDir, "setup-tools")
+	if err := os.MkdirAll(actionDir, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	content := "runs:\n  using: composite\n  steps: []\n"
+	if err := os.WriteFile(filepath.Join(actionDir, "action.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write action: %v", err)
+	}
+
+	actions, diags, err := ParseActions(tmpDir)
+	if err != nil {
+		t.Fatalf("ParseActions returned error: %v", err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("expected 1 action, got %d", len(actions))
+	}
+	if actions[0].Name != "setup-tools" {
+		t.Errorf("expected name derived from directory, got %q", actions[0].Name)
+	}
+
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 warnings, got %+v", diags)
+	}
+	for _, d := range diags {
+		if d.Severity != types.SeverityWarning || d.Code != ReasonMissingField {
+			t.Errorf("expected missing-field warning, got %s %s", d.Severity, d.Code)
+		}
+	}
+}
+
+func TestFallbackName(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{filepath.Join("actions", "deploy", "action.yml"), "deploy"},
+		{"action.yaml", "action"},
+	}
+	for _, tt := range tests {
+		if got := fallbackName(tt.path); got != tt.expected {
+			t.Errorf("fallbackName(%q) = %q, expected %q", tt.path, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tnaucoin/stringer/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Schema violations reported by ValidateAction
+const (
+	CodeUnknownKey         types.DiagnosticCode = "unknown-key"
+	CodeMissingShell       types.DiagnosticCode = "missing-shell"
+	CodeMissingRunOrUses   types.DiagnosticCode = "missing-run-or-uses"
+	CodeDuplicateStepID    types.DiagnosticCode = "duplicate-step-id"
+	CodeMissingOutputValue types.DiagnosticCode = "missing-output-value"
+	CodeMissingInputDesc   types.DiagnosticCode = "missing-input-description"
+	CodeInvalidBranding    types.DiagnosticCode = "invalid-branding"
+	CodeInvalidType        types.DiagnosticCode = "invalid-type"
+)
+
+var (
+	actionKeys      = keySet("name", "author", "description", "inputs", "outputs", "runs", "branding")
+	inputKeys       = keySet("description", "required", "default", "deprecationMessage")
+	outputKeys      = keySet("description", "value")
+	compositeKeys   = keySet("id", "name", "if", "uses", "run", "shell", "with", "env", "working-directory", "continue-on-error")
+	brandingKeys    = keySet("icon", "color")
+	brandingColors  = keySet("white", "black", "yellow", "blue", "green", "orange", "red", "purple", "gray-dark")
+	brandingIcons   = keySet(featherIcons...)
+	validShellNames = "bash, pwsh, python, sh, cmd, powershell or a custom command"
+)
+
+// ValidateAction checks an action metadata file against the rules of the
+// action metadata schema that ParseActionFromBytes doesn't enforce. It
+// keeps going after a violation and returns all of them with positions.
+func ValidateAction(data []byte, path string) []types.Diagnostic {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		pe := yamlError(ReasonInvalidYAML, "invalid yaml file", err)
+		return []types.Diagnostic{{File: path, Line: pe.Line, Severity: types.SeverityError, Code: pe.Reason, Message: pe.Message}}
+	}
+	root := documentRoot(&doc)
+	if root.Kind != yaml.MappingNode {
+		return []types.Diagnostic{violation(path, root, types.SeverityError, CodeInvalidType, "action metadata must be a map")}
+	}
+
+	v := &validator{path: path}
+	v.unknownKeys(root, actionKeys, "action metadata", types.SeverityError)
+
+	using := scalarValue(mappingValue(mappingValue(root, "runs"), "using"))
+	v.inputs(mappingValue(root, "inputs"))
+	v.outputs(mappingValue(root, "outputs"), using == "composite")
+	if using == "composite" {
+		v.compositeSteps(mappingValue(mappingValue(root, "runs"), "steps"))
+	}
+	v.branding(mappingValue(root, "branding"))
+
+	sort.SliceStable(v.diags, func(i, j int) bool {
+		if v.diags[i].Line != v.diags[j].Line {
+			return v.diags[i].Line < v.diags[j].Line
+		}
+		return v.diags[i].Column < v.diags[j].Column
+	})
+	return v.diags
+}
+
+type validator struct {
+	path  string
+	diags []types.Diagnostic
+}
+
+func (v *validator) report(node *yaml.Node, severity types.Severity, code types.DiagnosticCode, format string, args ...any) {
+	v.diags = append(v.diags, violation(v.path, node, severity, code, fmt.Sprintf(format, args...)))
+}
+
+// mapping reports a node that should be a map and returns whether it is
+func (v *validator) mapping(node *yaml.Node, what string) bool {
+	if node == nil || node.Tag == "!!null" {
+		return false
+	}
+	if node.Kind != yaml.MappingNode {
+		v.report(node, types.SeverityError, CodeInvalidType, "%s must be a map", what)
+		return false
+	}
+	return true
+}
+
+func (v *validator) unknownKeys(node *yaml.Node, allowed map[string]bool, what string, severity types.Severity) {
+	eachMappingPair(node, func(k, _ *yaml.Node) {
+		if !allowed[k.Value] {
+			v.report(k, severity, CodeUnknownKey, "unknown key %q in %s", k.Value, what)
+		}
+	})
+}
+
+func (v *validator) inputs(node *yaml.Node) {
+	if !v.mapping(node, "inputs") {
+		return
+	}
+	eachMappingPair(node, func(k, in *yaml.Node) {
+		what := fmt.Sprintf("input %q", k.Value)
+		if in.Tag == "!!null" || !v.mapping(in, what) {
+			v.report(k, types.SeverityWarning, CodeMissingInputDesc, "%s has no description", what)
+			return
+		}
+		v.unknownKeys(in, inputKeys, what, types.SeverityWarning)
+		if mappingValue(in, "description") == nil {
+			v.report(k, types.SeverityWarning, CodeMissingInputDesc, "%s has no description", what)
+		}
+	})
+}
+
+func (v *validator) outputs(node *yaml.Node, composite bool) {
+	if !v.mapping(node, "outputs") {
+		return
+	}
+	eachMappingPair(node, func(k, out *yaml.Node) {
+		what := fmt.Sprintf("output %q", k.Value)
+		if out.Tag != "!!null" && !v.mapping(out, what) {
+			return
+		}
+		v.unknownKeys(out, outputKeys, what, types.SeverityWarning)
+		if composite && scalarValue(mappingValue(out, "value")) == "" {
+			v.report(k, types.SeverityError, CodeMissingOutputValue, "%s of a composite action must set value", what)
+		}
+	})
+}
+
+func (v *validator) compositeSteps(node *yaml.Node) {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return
+	}
+	ids := map[string]*yaml.Node{}
+	for i, step := range node.Content {
+		what := fmt.Sprintf("step %d", i+1)
+		if !v.mapping(step, what) {
+			continue
+		}
+		v.unknownKeys(step, compositeKeys, "composite "+what, types.SeverityError)
+
+		run := mappingValue(step, "run")
+		uses := mappingValue(step, "uses")
+		switch {
+		case run == nil && uses == nil:
+			v.report(step, types.SeverityError, CodeMissingRunOrUses, "%s must set run or uses", what)
+		case run != nil && mappingValue(step, "shell") == nil:
+			v.report(run, types.SeverityError, CodeMissingShell, "%s runs a script and must set shell (%s)", what, validShellNames)
+		}
+
+		if id := mappingValue(step, "id"); id != nil {
+			if first, ok := ids[id.Value]; ok {
+				v.report(id, types.SeverityError, CodeDuplicateStepID, "step id %q is already used at line %d", id.Value, first.Line)
+			} else {
+				ids[id.Value] = id
+			}
+		}
+	}
+}
+
+func (v *validator) branding(node *yaml.Node) {
+	if !v.mapping(node, "branding") {
+		return
+	}
+	v.unknownKeys(node, brandingKeys, "branding", types.SeverityError)
+	if icon := mappingValue(node, "icon"); icon != nil && !brandingIcons[icon.Value] {
+		v.report(icon, types.SeverityError, CodeInvalidBranding, "unknown branding icon %q", icon.Value)
+	}
+	if color := mappingValue(node, "color"); color != nil && !brandingColors[color.Value] {
+		v.report(color, types.SeverityError, CodeInvalidBranding, "unknown branding color %q, expected one of %s", color.Value, strings.Join(sortedKeys(brandingColors), ", "))
+	}
+}
+
+func violation(path string, node *yaml.Node, severity types.Severity, code types.DiagnosticCode, message string) types.Diagnostic {
+	return types.Diagnostic{
+		File:     path,
+		Line:     node.Line,
+		Column:   node.Column,
+		Severity: severity,
+		Code:     code,
+		Message:  message,
+	}
+}
+
+func keySet(keys ...string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[k] = true
+	}
+	return set
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// featherIcons are the branding icons GitHub accepts, a subset of the
+// Feather icon set
+var featherIcons = []string{
+	"activity", "airplay", "alert-circle", "alert-octagon", "alert-triangle",
+	"align-center", "align-justify", "align-left", "align-right", "anchor",
+	"aperture", "archive", "arrow-down-circle", "arrow-down-left",
+	"arrow-down-right", "arrow-down", "arrow-left-circle", "arrow-left",
+	"arrow-right-circle", "arrow-right", "arrow-up-circle", "arrow-up-left",
+	"arrow-up-right", "arrow-up", "at-sign", "award", "bar-chart-2",
+	"bar-chart", "battery-charging", "battery", "bell-off", "bell",
+	"bluetooth", "bold", "book-open", "book", "bookmark", "box", "briefcase",
+	"calendar", "camera-off", "camera", "cast", "check-circle",
+	"check-square", "check", "chevron-down", "chevron-left", "chevron-right",
+	"chevron-up", "chevrons-down", "chevrons-left", "chevrons-right",
+	"chevrons-up", "circle", "clipboard", "clock", "cloud-drizzle",
+	"cloud-lightning", "cloud-off", "cloud-rain", "cloud-snow", "cloud",
+	"code", "command", "compass", "copy", "corner-down-left",
+	"corner-down-right", "corner-left-down", "corner-left-up",
+	"corner-right-down", "corner-right-up", "corner-up-left",
+	"corner-up-right", "cpu", "credit-card", "crop", "crosshair", "database",
+	"delete", "disc", "dollar-sign", "download-cloud", "download", "droplet",
+	"edit-2", "edit-3", "edit", "external-link", "eye-off", "eye",
+	"fast-forward", "feather", "file-minus", "file-plus", "file-text",
+	"file", "film", "filter", "flag", "folder-minus", "folder-plus",
+	"folder", "gift", "git-branch", "git-commit", "git-merge",
+	"git-pull-request", "globe", "grid", "hard-drive", "hash", "headphones",
+	"heart", "help-circle", "home", "image", "inbox", "info", "italic",
+	"layers", "layout", "life-buoy", "link-2", "link", "list", "loader",
+	"lock", "log-in", "log-out", "mail", "map-pin", "map", "maximize-2",
+	"maximize", "menu", "message-circle", "message-square", "mic-off", "mic",
+	"minimize-2", "minimize", "minus-circle", "minus-square", "minus",
+	"monitor", "moon", "more-horizontal", "more-vertical", "move", "music",
+	"navigation-2", "navigation", "octagon", "package", "paperclip",
+	"pause-circle", "pause", "percent", "phone-call", "phone-forwarded",
+	"phone-incoming", "phone-missed", "phone-off", "phone-outgoing", "phone",
+	"pie-chart", "play-circle", "play", "plus-circle", "plus-square", "plus",
+	"pocket", "power", "printer", "radio", "refresh-ccw", "refresh-cw",
+	"repeat", "rewind", "rotate-ccw", "rotate-cw", "rss", "save", "scissors",
+	"search", "send", "server", "settings", "share-2", "share", "shield-off",
+	"shield", "shopping-bag", "shopping-cart", "shuffle", "sidebar",
+	"skip-back", "skip-forward", "slash", "sliders", "smartphone", "speaker",
+	"square", "star", "stop-circle", "sun", "sunrise", "sunset", "table",
+	"tablet", "tag", "target", "terminal", "thermometer", "thumbs-down",
+	"thumbs-up", "toggle-left", "toggle-right", "trash-2", "trash",
+	"trending-down", "trending-up", "triangle", "truck", "tv", "type",
+	"umbrella", "underline", "unlock", "upload-cloud", "upload",
+	"user-check", "user-minus", "user-plus", "user-x", "user", "users",
+	"video-off", "video", "voicemail", "volume-1", "volume-2", "volume-x",
+	"volume", "watch", "wifi-off", "wifi", "wind", "x-circle", "x-square",
+	"x", "zap-off", "zap", "zoom-in", "zoom-out",
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+func TestValidateAction(t *testing.T) {
+	type violation struct {
+		code types.DiagnosticCode
+		line int
+	}
+	tests := []struct {
+		name     string
+		content  string
+		expected []violation
+	}{
+		{
+			name: "valid composite action",
+			content: `name: x
+description: y
+branding:
+  icon: package
+  color: blue
+inputs:
+  version:
+    description: version to install
+outputs:
+  path:
+    description: install path
+    value: ${{ steps.install.outputs.path }}
+runs:
+  using: composite
+  steps:
+    - id: install
+      run: ./install.sh
+      shell: bash
+    - uses: actions/cache@v4
+`,
+		},
+		{
+			name: "every violation is reported",
+			content: `name: x
+description: y
+colour: red
+branding:
+  icon: not-an-icon
+  color: pink
+outputs:
+  path:
+    description: install path
+runs:
+  using: composite
+  steps:
+    - id: build
+      run: make
+    - id: build
+      uses: actions/cache@v4
+      timeout-minutes: 5
+    - name: nothing to do
+`,
+			expected: []violation{
+				{CodeUnknownKey, 3},
+				{CodeInvalidBranding, 5},
+				{CodeInvalidBranding, 6},
+				{CodeMissingOutputValue, 8},
+				{CodeMissingShell, 14},
+				{CodeDuplicateStepID, 15},
+				{CodeUnknownKey, 17},
+				{CodeMissingRunOrUses, 18},
+			},
+		},
+		{
+			name: "output value is only required for composite actions",
+			content: `name: x
+description: y
+outputs:
+  path:
+    description: install path
+runs:
+  using: node20
+  main: index.js
+`,
+		},
+		{
+			name: "inputs without description",
+			content: `name: x
+description: y
+inputs:
+  token:
+  version:
+    default: latest
+    type: string
+runs:
+  using: node20
+  main: index.js
+`,
+			expected: []violation{
+				{CodeMissingInputDesc, 4},
+				{CodeMissingInputDesc, 5},
+				{CodeUnknownKey, 7},
+			},
+		},
+		{
+			name:     "not a map",
+			content:  "- name: x\n",
+			expected: []violation{{CodeInvalidType, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := ValidateAction([]byte(tt.content), "action.yml")
+			if len(diags) != len(tt.expected) {
+				t.Fatalf("expected %d violations, got %+v", len(tt.expected), diags)
+			}
+			for i, e := range tt.expected {
+				if diags[i].Code != e.code || diags[i].Line != e.line {
+					t.Errorf("violation %d: expected %s on line %d, got %s on line %d (%s)",
+						i, e.code, e.line, diags[i].Code, diags[i].Line, diags[i].Message)
+				}
+				if diags[i].File != "action.yml" {
+					t.Errorf("violation %d: expected file action.yml, got %q", i, diags[i].File)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tnaucoin/stringer/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseWorkflows scans a directory for workflow files, only YAML files
+// directly inside a .github/workflows directory are considered
+func ParseWorkflows(root string) ([]types.Workflow, error) {
+	var workflows []types.Workflow
+	repoRoot := FindRepoRoot(root)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && IsIgnoredDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !IsWorkflowPath(path) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		workflow, err := ParseWorkflowFromBytes(data, path)
+		if err != nil {
+			// composite actions are sometimes kept next to workflows
+			return nil
+		}
+		workflow.Root = repoRoot
+		workflows = append(workflows, workflow)
+		return nil
+	})
+	return workflows, err
+}
+
+// IsWorkflowPath reports whether path is a YAML file in .github/workflows
+func IsWorkflowPath(path string) bool {
+	if !IsYAMLFile(path) {
+		return false
+	}
+	dir := filepath.ToSlash(filepath.Dir(path))
+	return dir == ".github/workflows" || strings.HasSuffix(dir, "/.github/workflows")
+}
+
+// ParseWorkflowFromBytes parses a GitHub Actions workflow file
+func ParseWorkflowFromBytes(data []byte, path string) (types.Workflow, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return types.Workflow{}, fmt.Errorf("invalid yaml file")
+	}
+	root := documentRoot(&doc)
+	if root.Kind != yaml.MappingNode {
+		return types.Workflow{}, fmt.Errorf("not a workflow")
+	}
+
+	onNode := mappingValue(root, "on")
+	jobsNode := mappingValue(root, "jobs")
+	if onNode == nil || jobsNode == nil {
+		return types.Workflow{}, fmt.Errorf("not a workflow, missing on or jobs")
+	}
+
+	triggers, err := parseTriggers(onNode)
+	if err != nil {
+		return types.Workflow{}, err
+	}
+	jobs, err := parseJobs(jobsNode)
+	if err != nil {
+		return types.Workflow{}, err
+	}
+
+	return types.Workflow{
+		Name: scalarValue(mappingValue(root, "name")),
+		On:   triggers,
+		Jobs: jobs,
+		Path: path,
+	}, nil
+}
+
+// parseTriggers normalizes the three shapes of `on:`, a single event, a
+// list of events or a map of events to their configuration
+func parseTriggers(node *yaml.Node) ([]types.Trigger, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return []types.Trigger{{Event: node.Value}}, nil
+	case yaml.SequenceNode:
+		var triggers []types.Trigger
+		for _, n := range node.Content {
+			if n.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("invalid event at line %d", n.Line)
+			}
+			triggers = append(triggers, types.Trigger{Event: n.Value})
+		}
+		return triggers, nil
+	case yaml.MappingNode:
+		var triggers []types.Trigger
+		var err error
+		eachMappingPair(node, func(key, value *yaml.Node) {
+			t := types.Trigger{Event: key.Value}
+			if value.Tag != "!!null" {
+				if decodeErr := value.Decode(&t.Config); decodeErr != nil && err == nil {
+					err = fmt.Errorf("invalid %s trigger at line %d: %w", key.Value, value.Line, decodeErr)
+				}
+			}
+			triggers = append(triggers, t)
+		})
+		return triggers, err
+	}
+	return nil, fmt.Errorf("invalid on at line %d", node.Line)
+}
+
+// rawJob holds the job keys that need more than a plain decode
+type rawJob struct {
+	RunsOn yaml.Node   `yaml:"runs-on"`
+	Needs  yaml.Node   `yaml:"needs"`
+	Steps  []yaml.Node `yaml:"steps"`
+}
+
+func parseJobs(node *yaml.Node) ([]types.Job, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("jobs must be a map (line %d)", node.Line)
+	}
+	var jobs []types.Job
+	var err error
+	eachMappingPair(node, func(key, value *yaml.Node) {
+		if err != nil {
+			return
+		}
+		var job types.Job
+		job, err = parseJob(key, value)
+		jobs = append(jobs, job)
+	})
+	return jobs, err
+}
+
+func parseJob(key, node *yaml.Node) (types.Job, error) {
+	id := key.Value
+	var raw rawJob
+	if err := node.Decode(&raw); err != nil {
+		return types.Job{}, fmt.Errorf("invalid job %s at line %d: %w", id, node.Line, err)
+	}
+
+	// decode the plain fields with the complex ones blanked out, they
+	// don't fit the typed fields directly
+	plain := *node
+	plain.Content = nil
+	eachMappingPair(node, func(k, v *yaml.Node) {
+		switch k.Value {
+		case "runs-on", "needs", "steps":
+			return
+		}
+		plain.Content = append(plain.Content, k, v)
+	})
+	var job types.Job
+	if err := plain.Decode(&job); err != nil {
+		return types.Job{}, fmt.Errorf("invalid job %s at line %d: %w", id, node.Line, err)
+	}
+	job.ID = id
+	job.Line = key.Line
+	job.RunsOn = parseRunsOn(&raw.RunsOn)
+	job.Needs = stringList(&raw.Needs)
+
+	for _, n := range raw.Steps {
+		var step types.Step
+		if err := n.Decode(&step); err != nil {
+			return types.Job{}, fmt.Errorf("invalid step in job %s at line %d: %w", id, n.Line, err)
+		}
+		setStepPositions(&step, &n)
+		job.Steps = append(job.Steps, step)
+	}
+	return job, nil
+}
+
+// setStepPositions records where the step and its `with:` keys are
+func setStepPositions(step *types.Step, node *yaml.Node) {
+	step.Line = node.Line
+	eachMappingPair(mappingValue(node, "with"), func(k, _ *yaml.Node) {
+		if step.WithLines == nil {
+			step.WithLines = map[string]int{}
+		}
+		step.WithLines[k.Value] = k.Line
+	})
+}
+
+// parseRunsOn accepts a label, a list of labels or a group/labels map
+func parseRunsOn(node *yaml.Node) []string {
+	if node.Kind != yaml.MappingNode {
+		return stringList(node)
+	}
+	var labels []string
+	if group := scalarValue(mappingValue(node, "group")); group != "" {
+		labels = append(labels, "group:"+group)
+	}
+	return append(labels, stringList(mappingValue(node, "labels"))...)
+}
+
+// stringList reads a scalar or a sequence of scalars
+func stringList(node *yaml.Node) []string {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if v := scalarValue(node); v != "" {
+			return []string{v}
+		}
+	case yaml.SequenceNode:
+		var values []string
+		for _, n := range node.Content {
+			if v := scalarValue(n); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tnaucoin/stringer/types"
+)
+
+func TestParseWorkflowTriggers(t *testing.T) {
+	tests := []struct {
+		name     string
+		on       string
+		expected []types.Trigger
+	}{
+		{
+			name:     "single event",
+			on:       "on: push",
+			expected: []types.Trigger{{Event: "push"}},
+		},
+		{
+			name:     "list of events",
+			on:       "on: [push, pull_request]",
+			expected: []types.Trigger{{Event: "push"}, {Event: "pull_request"}},
+		},
+		{
+			name: "map of events",
+			on: `on:
+  push:
+    branches: [main]
+  workflow_dispatch:
+  schedule:
+    - cron: "0 0 * * *"`,
+			expected: []types.Trigger{
+				{Event: "push", Config: map[string]any{"branches": []any{"main"}}},
+				{Event: "workflow_dispatch"},
+				{Event: "schedule", Config: []any{map[string]any{"cron": "0 0 * * *"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := tt.on + `
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo hi
+`
+			workflow, err := ParseWorkflowFromBytes([]byte(content), "ci.yml")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(workflow.On, tt.expected) {
+				t.Errorf("expected triggers %+v, got %+v", tt.expected, workflow.On)
+			}
+		})
+	}
+}
+
+func TestParseWorkflowJobs(t *testing.T) {
+	content := `
+name: CI
+on: push
+jobs:
+  test:
+    name: Test
+    runs-on: [self-hosted, linux]
+    strategy:
+      fail-fast: false
+      matrix:
+        go: ["1.23", "1.24"]
+    steps:
+      - uses: actions/checkout@v4
+      - uses: ./.github/actions/greet
+        with:
+          name: ${{ matrix.go }}
+  deploy:
+    needs: test
+    if: github.ref == 'refs/heads/main'
+    runs-on:
+      group: deployers
+      labels: prod
+    env:
+      STAGE: prod
+    steps:
+      - run: ./deploy.sh
+  release:
+    needs: [test, deploy]
+    uses: my-org/workflows/.github/workflows/release.yml@v1
+    with:
+      version: 1.2.3
+    secrets: inherit
+`
+	workflow, err := ParseWorkflowFromBytes([]byte(content), ".github/workflows/ci.yml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if workflow.Name != "CI" || workflow.Path != ".github/workflows/ci.yml" {
+		t.Errorf("unexpected name %q or path %q", workflow.Name, workflow.Path)
+	}
+
+	expected := []types.Job{
+		{
+			ID:     "test",
+			Name:   "Test",
+			RunsOn: []string{"self-hosted", "linux"},
+			Strategy: &types.Strategy{
+				FailFast: "false",
+				Matrix:   map[string]any{"go": []any{"1.23", "1.24"}},
+			},
+			Steps: []types.Step{
+				{Uses: "actions/checkout@v4", Line: 13},
+				{Uses: "./.github/actions/greet", With: map[string]string{"name": "${{ matrix.go }}"}, Line: 14, WithLines: map[string]int{"name": 16}},
+			},
+			Line: 5,
+		},
+		{
+			ID:     "deploy",
+			RunsOn: []string{"group:deployers", "prod"},
+			Needs:  []string{"test"},
+			If:     "github.ref == 'refs/heads/main'",
+			Env:    map[string]string{"STAGE": "prod"},
+			Steps:  []types.Step{{Run: "./deploy.sh", Line: 26}},
+			Line:   17,
+		},
+		{
+			ID:      "release",
+			Needs:   []string{"test", "deploy"},
+			Uses:    "my-org/workflows/.github/workflows/release.yml@v1",
+			With:    map[string]string{"version": "1.2.3"},
+			Secrets: "inherit",
+			Line:    27,
+		},
+	}
+	if len(workflow.Jobs) != len(expected) {
+		t.Fatalf("expected %d jobs, got %d", len(expected), len(workflow.Jobs))
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(workflow.Jobs[i], expected[i]) {
+			t.Errorf("job %d: expected %+v, got %+v", i, expected[i], workflow.Jobs[i])
+		}
+	}
+}
+
+func TestParseWorkflowFromBytesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"invalid yaml", "on: [push"},
+		{"composite action", "name: x\nruns:\n  using: composite\n"},
+		{"missing jobs", "on: push\n"},
+		{"jobs not a map", "on: push\njobs: [build]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseWorkflowFromBytes([]byte(tt.content), "ci.yml"); err == nil {
+				t.Errorf("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestParseWorkflows(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	if err := os.MkdirAll(workflowsDir, 0755); err != nil {
+		t.Fatalf("failed to create workflows dir: %v", err)
+	}
+
+	files := map[string]string{
+		filepath.Join(workflowsDir, "ci.yml"):       "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+		filepath.Join(workflowsDir, "action.yml"):   "name: x\ndescription: y\nruns:\n  using: composite\n",
+		filepath.Join(tmpDir, "not-a-workflow.yml"): "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	workflows, err := ParseWorkflows(tmpDir)
+	if err != nil {
+		t.Fatalf("ParseWorkflows returned error: %v", err)
+	}
+	if len(workflows) != 1 {
+		t.Fatalf("expected 1 workflow, got %d", len(workflows))
+	}
+	if filepath.Base(workflows[0].Path) != "ci.yml" {
+		t.Errorf("expected ci.yml, got %s", workflows[0].Path)
+	}
+}
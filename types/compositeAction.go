@@ -1,9 +1,234 @@
 package types
 
+import (
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CompositeAction is a parsed action metadata file. It started out as
+// composite-only and now holds every action kind, Kind tells them apart.
 type CompositeAction struct {
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	Inputs      map[string]any `json:"inputs"`
-	Outputs     map[string]any `json:"outputs"`
-	Path        string         `json:"-"`
+	Kind        ActionKind    `json:"kind" yaml:"kind"`
+	Name        string        `json:"name" yaml:"name"`
+	Description string        `json:"description" yaml:"description"`
+	Inputs      ActionInputs  `json:"inputs" yaml:"inputs"`
+	Outputs     ActionOutputs `json:"outputs" yaml:"outputs"`
+	Runs        Runs          `json:"runs" yaml:"runs"`
+	Path        string        `json:"-" yaml:"-"`
+	Provenance  Provenance    `json:"provenance" yaml:"provenance"`
+}
+
+type ActionKind string
+
+const (
+	KindComposite  ActionKind = "composite"
+	KindJavaScript ActionKind = "javascript"
+	KindDocker     ActionKind = "docker"
+)
+
+// KindFromUsing maps a runs.using value to the action kind it declares
+func KindFromUsing(using string) (ActionKind, bool) {
+	switch {
+	case using == "composite":
+		return KindComposite, true
+	case using == "docker":
+		return KindDocker, true
+	case strings.HasPrefix(using, "node"):
+		return KindJavaScript, true
+	}
+	return "", false
+}
+
+// Runs is the runs block of an action, which fields are set depends on
+// the action kind
+type Runs struct {
+	Using string `json:"using" yaml:"using"`
+
+	// composite actions
+	Steps []Step `json:"steps,omitempty" yaml:"steps,omitempty"`
+
+	// javascript actions
+	Main   string `json:"main,omitempty" yaml:"main,omitempty"`
+	Pre    string `json:"pre,omitempty" yaml:"pre,omitempty"`
+	PreIf  string `json:"pre-if,omitempty" yaml:"pre-if,omitempty"`
+	Post   string `json:"post,omitempty" yaml:"post,omitempty"`
+	PostIf string `json:"post-if,omitempty" yaml:"post-if,omitempty"`
+
+	// docker actions
+	Image          string            `json:"image,omitempty" yaml:"image,omitempty"`
+	Entrypoint     string            `json:"entrypoint,omitempty" yaml:"entrypoint,omitempty"`
+	PreEntrypoint  string            `json:"pre-entrypoint,omitempty" yaml:"pre-entrypoint,omitempty"`
+	PostEntrypoint string            `json:"post-entrypoint,omitempty" yaml:"post-entrypoint,omitempty"`
+	Args           []string          `json:"args,omitempty" yaml:"args,omitempty"`
+	Env            map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+// Calls returns the distinct `uses:` references of the action's steps in
+// the order they are first called
+func (a CompositeAction) Calls() []string {
+	var calls []string
+	seen := map[string]bool{}
+	for _, step := range a.Runs.Steps {
+		if step.Uses == "" || seen[step.Uses] {
+			continue
+		}
+		seen[step.Uses] = true
+		calls = append(calls, step.Uses)
+	}
+	return calls
+}
+
+// ID is a stable identifier derived from where the action was found, it
+// only contains lower case letters, digits and dashes so it can be used
+// in file names and URLs
+func (a CompositeAction) ID() string {
+	file := a.Provenance.Path
+	if file == "" {
+		file = a.Path
+	}
+	file = path.Clean("/" + filepath.ToSlash(file))
+	if base := path.Base(file); base == "action.yml" || base == "action.yaml" {
+		file = path.Dir(file)
+	} else {
+		file = strings.TrimSuffix(file, path.Ext(file))
+	}
+	id := slug(a.Provenance.Repo + "/" + file)
+	if id == "" {
+		id = slug(a.Name)
+	}
+	return id
+}
+
+// ActionIDs returns the ID of every action, actions that would get the
+// same ID are told apart by a numeric suffix in catalog order
+func ActionIDs(actions []CompositeAction) []string {
+	ids := make([]string, len(actions))
+	seen := map[string]int{}
+	for i, a := range actions {
+		id := a.ID()
+		if id == "" {
+			id = "action"
+		}
+		seen[id]++
+		if n := seen[id]; n > 1 {
+			id += "-" + strconv.Itoa(n)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+func slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+type ActionInput struct {
+	Name               string `json:"name" yaml:"name"`
+	Description        string `json:"description,omitempty" yaml:"description,omitempty"`
+	Required           bool   `json:"required" yaml:"required"`
+	Default            string `json:"default,omitempty" yaml:"default,omitempty"`
+	DeprecationMessage string `json:"deprecationMessage,omitempty" yaml:"deprecationMessage,omitempty"`
+	// Line is where the input is declared in action.yml
+	Line int `json:"line,omitempty" yaml:"-"`
+}
+
+type ActionOutput struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Value is the expression a composite action output is mapped from
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+	// Line is where the output is declared in action.yml
+	Line int `json:"line,omitempty" yaml:"-"`
+}
+
+// ActionInputs keeps inputs in the order they are declared in action.yml
+type ActionInputs []ActionInput
+
+// Get returns the input with the given name, input names are case insensitive
+func (in ActionInputs) Get(name string) (ActionInput, bool) {
+	for _, i := range in {
+		if strings.EqualFold(i.Name, name) {
+			return i, true
+		}
+	}
+	return ActionInput{}, false
+}
+
+// ActionOutputs keeps outputs in the order they are declared in action.yml
+type ActionOutputs []ActionOutput
+
+func (out ActionOutputs) Get(name string) (ActionOutput, bool) {
+	for _, o := range out {
+		if strings.EqualFold(o.Name, name) {
+			return o, true
+		}
+	}
+	return ActionOutput{}, false
+}
+
+type SourceKind string
+
+const (
+	SourceLocal  SourceKind = "local"
+	SourceRemote SourceKind = "remote"
+)
+
+// Provenance records where an action was discovered and how workflows
+// should reference it
+type Provenance struct {
+	Kind SourceKind `json:"kind" yaml:"kind"`
+	Repo string     `json:"repo,omitempty" yaml:"repo,omitempty"`
+	Ref  string     `json:"ref,omitempty" yaml:"ref,omitempty"`
+	SHA  string     `json:"sha,omitempty" yaml:"sha,omitempty"`
+	// Root is the absolute root of the checkout a local action was found
+	// in, ./ references only resolve to actions of the same root
+	Root string `json:"root,omitempty" yaml:"root,omitempty"`
+	// Path is the action file relative to the repository root
+	Path string `json:"path" yaml:"path"`
+	// Uses is the reference consumers write in a step's `uses:`, it is
+	// empty when the file can't be referenced (not named action.yml)
+	Uses string `json:"uses,omitempty" yaml:"uses,omitempty"`
+}
+
+// UsesReference builds the `uses:` string for the action file at path.
+// Remote actions are referenced as owner/repo/dir@ref, local ones (empty
+// repo) as ./dir relative to the repository root.
+func UsesReference(repo, ref, file string) string {
+	file = strings.TrimPrefix(path.Clean("/"+file), "/")
+	base := path.Base(file)
+	if base != "action.yml" && base != "action.yaml" {
+		return ""
+	}
+	dir := path.Dir(file)
+
+	if repo == "" {
+		if dir == "." {
+			return "./"
+		}
+		return "./" + dir
+	}
+
+	uses := repo
+	if dir != "." {
+		uses += "/" + dir
+	}
+	if ref != "" {
+		uses += "@" + ref
+	}
+	return uses
 }
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUsesReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		repo     string
+		ref      string
+		file     string
+		expected string
+	}{
+		{"remote nested action", "my-org/actions", "v1", "greet/action.yml", "my-org/actions/greet@v1"},
+		{"remote root action", "my-org/greet", "main", "action.yaml", "my-org/greet@main"},
+		{"remote without ref", "my-org/greet", "", "action.yml", "my-org/greet"},
+		{"local nested action", "", "", ".github/actions/greet/action.yml", "./.github/actions/greet"},
+		{"local root action", "", "", "action.yml", "./"},
+		{"not an action file", "my-org/actions", "v1", "greet/greet.yml", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UsesReference(tt.repo, tt.ref, tt.file); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompositeActionID(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   CompositeAction
+		expected string
+	}{
+		{"remote nested action", CompositeAction{Provenance: Provenance{Repo: "My-Org/actions", Path: "greet/action.yml"}}, "my-org-actions-greet"},
+		{"remote root action", CompositeAction{Provenance: Provenance{Repo: "my-org/greet", Path: "action.yaml"}}, "my-org-greet"},
+		{"local action", CompositeAction{Provenance: Provenance{Path: ".github/actions/set_up/action.yml"}}, "github-actions-set-up"},
+		{"other file name", CompositeAction{Provenance: Provenance{Path: "ci/deploy.yml"}}, "ci-deploy"},
+		{"root action falls back to name", CompositeAction{Name: "Greet User", Provenance: Provenance{Path: "action.yml"}}, "greet-user"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.action.ID(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestActionIDs(t *testing.T) {
+	actions := []CompositeAction{
+		{Provenance: Provenance{Path: "deploy/action.yml"}},
+		{Provenance: Provenance{Path: "deploy/action.yaml"}},
+		{Provenance: Provenance{Path: "build/action.yml"}},
+		{},
+	}
+	expected := []string{"deploy", "deploy-2", "build", "action"}
+	got := ActionIDs(actions)
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected ids %v, got %v", expected, got)
+			break
+		}
+	}
+}
+
+func TestCompositeActionUnmarshalKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected ActionKind
+	}{
+		{"written before kinds", `{"name": "a", "runs": {"using": "composite"}}`, KindComposite},
+		{"javascript", `{"kind": "javascript", "name": "a", "runs": {"using": "node20"}}`, KindJavaScript},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a CompositeAction
+			if err := json.Unmarshal([]byte(tt.data), &a); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if a.Kind != tt.expected || a.Name != "a" {
+				t.Errorf("expected kind %q, got %+v", tt.expected, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Older versions of stringer stored inputs and outputs as the raw YAML
+// maps, e.g. {"name": {"description": "...", "required": true}}. The
+// UnmarshalJSON methods below accept both that and the current list form
+// so existing cache and output files keep loading.
+
+// legacyEntry is one value of the legacy input/output map
+type legacyEntry struct {
+	Description        string `json:"description"`
+	Required           any    `json:"required"`
+	Default            any    `json:"default"`
+	DeprecationMessage string `json:"deprecationMessage"`
+	Value              string `json:"value"`
+}
+
+func (in *ActionInputs) UnmarshalJSON(data []byte) error {
+	if !isJSONObject(data) {
+		return json.Unmarshal(data, (*[]ActionInput)(in))
+	}
+	*in = nil
+	return decodeLegacyMap(data, func(name string, e legacyEntry) {
+		*in = append(*in, ActionInput{
+			Name:               name,
+			Description:        e.Description,
+			Required:           legacyBool(e.Required),
+			Default:            legacyString(e.Default),
+			DeprecationMessage: e.DeprecationMessage,
+		})
+	})
+}
+
+func (out *ActionOutputs) UnmarshalJSON(data []byte) error {
+	if !isJSONObject(data) {
+		return json.Unmarshal(data, (*[]ActionOutput)(out))
+	}
+	*out = nil
+	return decodeLegacyMap(data, func(name string, e legacyEntry) {
+		*out = append(*out, ActionOutput{
+			Name:        name,
+			Description: e.Description,
+			Value:       e.Value,
+		})
+	})
+}
+
+// UnmarshalJSON defaults a missing kind to composite, actions were only
+// cataloged when they were composite before Kind existed
+func (a *CompositeAction) UnmarshalJSON(data []byte) error {
+	// plain has the fields but not the methods, so this doesn't recurse
+	type plain CompositeAction
+	if err := json.Unmarshal(data, (*plain)(a)); err != nil {
+		return err
+	}
+	if a.Kind == "" {
+		a.Kind = KindComposite
+	}
+	return nil
+}
+
+func isJSONObject(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
+
+// decodeLegacyMap walks a legacy map in file order, json.Unmarshal into a
+// Go map would lose the declaration order
+func decodeLegacyMap(data []byte, fn func(name string, e legacyEntry)) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("unexpected key %v in legacy map", tok)
+		}
+		var e legacyEntry
+		if err := dec.Decode(&e); err != nil {
+			return fmt.Errorf("invalid legacy entry %q: %w", name, err)
+		}
+		fn(name, e)
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func legacyBool(v any) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		parsed, _ := strconv.ParseBool(b)
+		return parsed
+	}
+	return false
+}
+
+func legacyString(v any) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	default:
+		return fmt.Sprint(s)
+	}
+}
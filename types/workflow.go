@@ -1,20 +1,73 @@
 package types
 
 type Workflow struct {
-	Name string         `json:"name" yaml:"name"`
-	On   map[string]any `json:"on" yaml:"on"`
-	Jobs map[string]any `json:"jobs" yaml:"jobs"`
+	Name string    `json:"name" yaml:"name"`
+	On   []Trigger `json:"on" yaml:"on"`
+	Jobs []Job     `json:"jobs" yaml:"jobs"`
+	Path string    `json:"path" yaml:"-"`
+	// Repo and Ref are set for workflows fetched from GitHub, Root for
+	// workflows of a local checkout
+	Repo string `json:"repo,omitempty" yaml:"-"`
+	Ref  string `json:"ref,omitempty" yaml:"-"`
+	Root string `json:"root,omitempty" yaml:"-"`
+}
+
+// Trigger is one event of a workflow's `on:` block. Config holds whatever
+// the event was configured with (branches, types, inputs, cron entries...)
+// and is nil for events listed without configuration.
+type Trigger struct {
+	Event  string `json:"event" yaml:"event"`
+	Config any    `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// Events returns the names of the events that trigger the workflow
+func (w Workflow) Events() []string {
+	var events []string
+	for _, t := range w.On {
+		events = append(events, t.Event)
+	}
+	return events
 }
 
 type Job struct {
-	Name   string `json:"name" yaml:"name"`
-	RunsOn string `json:"runs-on" yaml:"runs-on"`
-	Steps  []Step `json:"steps" yaml:"steps"`
+	ID     string   `json:"id" yaml:"-"`
+	Name   string   `json:"name" yaml:"name"`
+	RunsOn []string `json:"runs-on,omitempty" yaml:"runs-on"`
+	Needs  []string `json:"needs,omitempty" yaml:"needs,omitempty"`
+	If     string   `json:"if,omitempty" yaml:"if,omitempty"`
+	// Uses, With and Secrets are set on jobs calling a reusable workflow
+	Uses     string            `json:"uses,omitempty" yaml:"uses,omitempty"`
+	With     map[string]string `json:"with,omitempty" yaml:"with,omitempty"`
+	Secrets  any               `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+	Strategy *Strategy         `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	Env      map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	Steps    []Step            `json:"steps" yaml:"steps"`
+	Line     int               `json:"-" yaml:"-"`
+}
+
+type Strategy struct {
+	// Matrix is usually a map of axes plus include/exclude lists, but may
+	// also be a single expression such as ${{ fromJSON(...) }}
+	Matrix      any    `json:"matrix,omitempty" yaml:"matrix,omitempty"`
+	FailFast    string `json:"fail-fast,omitempty" yaml:"fail-fast,omitempty"`
+	MaxParallel string `json:"max-parallel,omitempty" yaml:"max-parallel,omitempty"`
 }
 
 type Step struct {
-	Name string            `json:"name" yaml:"name"`
-	Uses string            `json:"uses,omitempty" yaml:"uses,omitempty"`
-	Run  string            `json:"run,omitempty" yaml:"run,omitempty"`
-	With map[string]string `json:"with,omitempty" yaml:"with,omitempty"`
+	ID               string            `json:"id,omitempty" yaml:"id,omitempty"`
+	Name             string            `json:"name" yaml:"name"`
+	If               string            `json:"if,omitempty" yaml:"if,omitempty"`
+	Uses             string            `json:"uses,omitempty" yaml:"uses,omitempty"`
+	Run              string            `json:"run,omitempty" yaml:"run,omitempty"`
+	Shell            string            `json:"shell,omitempty" yaml:"shell,omitempty"`
+	With             map[string]string `json:"with,omitempty" yaml:"with,omitempty"`
+	Env              map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	WorkingDirectory string            `json:"working-directory,omitempty" yaml:"working-directory,omitempty"`
+	// ContinueOnError is kept as text since it may be an expression
+	ContinueOnError string `json:"continue-on-error,omitempty" yaml:"continue-on-error,omitempty"`
+
+	// Line is where the step starts in its file and WithLines where each
+	// `with:` key is, both are zero when unknown
+	Line      int            `json:"line,omitempty" yaml:"-"`
+	WithLines map[string]int `json:"with-lines,omitempty" yaml:"-"`
 }